@@ -4,12 +4,21 @@ import (
 	"bufio"
 	"fmt"
 	"io"
+	"io/ioutil"
 	"os"
+	"path"
+	"path/filepath"
+	"sort"
 	"strings"
 )
 
 // AnalyzeCoverage applies the configured options to the profile data to produce report data.
 func AnalyzeCoverage(profile *CoverageProfile, opts EnforcerOptions) (AnalyzerResult, error) {
+	if !profile.HasExecutionCounts() && (opts.MinHits > 1 || opts.ColdBlockHits > 0 || opts.HotBlockCount > 0) {
+		return AnalyzerResult{}, fmt.Errorf(`-minhits, -showcold, and -showhot require a coverage profile in "count" or "atomic" mode (use "go test -covermode=count"); this profile's mode is "%s"`,
+			profile.CoverageMode)
+	}
+
 	blocks := profile.GetUniqueBlocks()
 
 	var result AnalyzerResult
@@ -33,12 +42,18 @@ func AnalyzeCoverage(profile *CoverageProfile, opts EnforcerOptions) (AnalyzerRe
 		}
 
 		if opts.SkipFilesPattern != nil && opts.SkipFilesPattern.MatchString(filePath) {
-			result.SkippedBlocks = append(result.SkippedBlocks, b)
-			result.SkippedFilePaths = append(result.SkippedFilePaths, b.CodeRange.FilePath)
+			result.SkippedBlocks = append(result.SkippedBlocks, SkippedBlock{
+				CodeBlockCoverage: b,
+				Reason:            SkipReason{Option: "skipfiles", Pattern: opts.SkipFilesPattern.String()},
+			})
+			if n := len(result.SkippedFilePaths); n == 0 || result.SkippedFilePaths[n-1] != b.CodeRange.FilePath {
+				result.SkippedFilePaths = append(result.SkippedFilePaths, b.CodeRange.FilePath)
+			}
 			continue
 		}
 
-		if currentFile == nil || fileName != currentFile.FileName {
+		if currentFile == nil || fileName != currentFile.FileName ||
+			relativePackagePath != currentPackage.RelativePath {
 			if currentFile != nil {
 				currentPackage.Files = append(currentPackage.Files, *currentFile)
 			}
@@ -51,41 +66,58 @@ func AnalyzeCoverage(profile *CoverageProfile, opts EnforcerOptions) (AnalyzerRe
 			currentPackage = &AnalyzerPackageResult{RelativePath: relativePackagePath}
 		}
 
-		if b.CoverageCount > 0 {
+		if isCovered(b.CoverageCount, getMinHits(opts)) {
+			currentFile.Blocks = append(currentFile.Blocks, b)
 			currentFile.TotalStatements += b.StatementCount
 			currentFile.CoveredStatements += b.StatementCount
 			continue
 		}
 
-		var showLines []string
+		ub := UncoveredBlock{CodeRange: b.CodeRange, StatementCount: b.StatementCount, CoverageCount: b.CoverageCount}
 		if opts.ShowCode || opts.SkipCodePattern != nil {
-			lines, err := readFileLines(filePath, b.CodeRange.StartLine, b.CodeRange.EndLine)
+			var contextBefore, contextAfter int
+			if opts.ShowCode {
+				contextBefore, contextAfter = opts.ContextLines, opts.ContextLines
+				if contextBefore >= b.CodeRange.StartLine {
+					contextBefore = b.CodeRange.StartLine - 1
+				}
+			}
+			sourcePath := filepath.Join(opts.SourceDir, filePath)
+			allLines, err := readFileLines(sourcePath, b.CodeRange.StartLine-contextBefore, b.CodeRange.EndLine+contextAfter)
 			if err != nil {
-				return result, fmt.Errorf(`unable to read file "%s" (%s)`, filePath, err)
+				return result, fmt.Errorf(`unable to read file "%s" (%s)`, sourcePath, err)
 			}
+			leading, lines, trailing := splitContextLines(allLines, contextBefore, b.CodeRange.EndLine-b.CodeRange.StartLine+1)
 
 			if opts.SkipCodePattern != nil {
-				found := false
-				for _, line := range lines {
+				found := -1
+				for i, line := range lines {
 					if opts.SkipCodePattern.FindString(line) != "" {
-						found = true
+						found = i
 						break
 					}
 				}
-				if found {
-					result.SkippedBlocks = append(result.SkippedBlocks, b)
+				if found >= 0 {
+					result.SkippedBlocks = append(result.SkippedBlocks, SkippedBlock{
+						CodeBlockCoverage: b,
+						Reason: SkipReason{
+							Option:  "skipcode",
+							Pattern: opts.SkipCodePattern.String(),
+							Line:    b.CodeRange.StartLine + found,
+							Text:    lines[found],
+						},
+					})
 					continue
 				}
 			}
 
 			if opts.ShowCode {
-				showLines = lines
+				ub.Text, ub.LeadingContext, ub.TrailingContext = lines, leading, trailing
 			}
 		}
 
+		currentFile.Blocks = append(currentFile.Blocks, b)
 		currentFile.TotalStatements += b.StatementCount
-
-		ub := UncoveredBlock{CodeRange: b.CodeRange, Text: showLines}
 		currentFile.UncoveredBlocks = append(currentFile.UncoveredBlocks, ub)
 	}
 
@@ -95,6 +127,26 @@ func AnalyzeCoverage(profile *CoverageProfile, opts EnforcerOptions) (AnalyzerRe
 	if currentPackage != nil {
 		result.Packages = append(result.Packages, *currentPackage)
 	}
+	if opts.SkipSourcePostPass {
+		return result, nil
+	}
+
+	for i, p := range result.Packages {
+		for j, f := range p.Files {
+			sourcePath := filepath.Join(opts.SourceDir, p.RelativePath, f.FileName)
+			file := &result.Packages[i].Files[j]
+			if shouldCheckStaleRanges(opts) {
+				profileFilePath := path.Join(opts.PackagePath, p.RelativePath, f.FileName)
+				result.StaleRanges = append(result.StaleRanges,
+					checkFileForStaleRanges(*file, profileFilePath, result.SkippedBlocks, sourcePath)...)
+			}
+			addFunctionInfo(file, sourcePath, getMinHits(opts))
+			if opts.MergeBlocks {
+				source, _ := ioutil.ReadFile(sourcePath) // if it can't be read, only adjacent blocks are merged
+				file.UncoveredBlocks = mergeUncoveredBlocks(file.UncoveredBlocks, source)
+			}
+		}
+	}
 
 	return result, nil
 }
@@ -117,6 +169,58 @@ func (ar AnalyzerResult) WriteFilteredProfile(
 	return filteredProfile.WriteTo(writer)
 }
 
+// checkFileForStaleRanges compares all of the file's blocks, including any that were skipped with
+// "-skipcode", to the current source code. If the file cannot be read, nothing is checked.
+func checkFileForStaleRanges(
+	file AnalyzerFileResult,
+	profileFilePath string,
+	skippedBlocks []SkippedBlock,
+	sourcePath string,
+) []StaleRange {
+	source, err := ioutil.ReadFile(sourcePath)
+	if err != nil {
+		return nil
+	}
+	blocks := append([]CodeBlockCoverage(nil), file.Blocks...)
+	for _, sb := range skippedBlocks {
+		if sb.CodeRange.FilePath == profileFilePath {
+			blocks = append(blocks, sb.CodeBlockCoverage)
+		}
+	}
+	sort.SliceStable(blocks, func(i, j int) bool {
+		a, b := blocks[i].CodeRange, blocks[j].CodeRange
+		return a.StartLine < b.StartLine || (a.StartLine == b.StartLine && a.StartColumn < b.StartColumn)
+	})
+	ranges := make([]CodeRange, 0, len(blocks))
+	statementCounts := make([]int, 0, len(blocks))
+	for _, b := range blocks {
+		ranges = append(ranges, b.CodeRange)
+		statementCounts = append(statementCounts, b.StatementCount)
+	}
+	return findStaleRanges(ranges, statementCounts, source)
+}
+
+// splitContextLines divides lines that were read from a file into the given number of leading
+// context lines, the given number of lines within a block, and any remaining trailing context
+// lines. If the file was shorter than expected, the later parts are shorter or empty.
+func splitContextLines(lines []string, leadingCount, blockCount int) (leading, block, trailing []string) {
+	if leadingCount > len(lines) {
+		leadingCount = len(lines)
+	}
+	leading, lines = lines[:leadingCount], lines[leadingCount:]
+	if blockCount > len(lines) {
+		blockCount = len(lines)
+	}
+	block, trailing = lines[:blockCount], lines[blockCount:]
+	if len(leading) == 0 {
+		leading = nil
+	}
+	if len(trailing) == 0 {
+		trailing = nil
+	}
+	return leading, block, trailing
+}
+
 func readFileLines(path string, start, end int) ([]string, error) {
 	f, err := os.Open(path)
 	if err != nil {
@@ -8,11 +8,17 @@ type AnalyzerResult struct {
 
 	// SkippedFilePaths is a list of file paths that were skipped due to the "-skipfiles" option.
 	// These are in the same format as in the coverage profile, so they include the package's
-	// import path.
+	// import path. Each path appears only once.
 	SkippedFilePaths []string
 
-	// SkippedBlocks is a list of code ranges that were skipped due to the "-skipcode" option.
-	SkippedBlocks []CodeBlockCoverage
+	// SkippedBlocks is a list of code ranges that were skipped due to either the "-skipfiles" or
+	// the "-skipcode" option.
+	SkippedBlocks []SkippedBlock
+
+	// StaleRanges is a list of code ranges in the profile that do not match the current source
+	// code, in the order they appear in the profile. It is only computed if the "-stale" option is
+	// "warn" or "fail".
+	StaleRanges []StaleRange
 }
 
 // AnalyzerPackageResult is package-level information in AnalyzerResult.
@@ -40,18 +46,95 @@ type AnalyzerFileResult struct {
 	// reported as covered in the coverage profile.
 	CoveredStatements int
 
+	// Blocks are all of the code blocks in this file, covered or not, in ascending order of starting
+	// line number. Duplicate blocks in the coverage profile are combined as described for
+	// CoverageProfile.GetUniqueBlocks. It does not include any blocks that were skipped with
+	// "-skipcode".
+	Blocks []CodeBlockCoverage
+
+	// Functions contains statistics for each top-level function or method in this file that
+	// contains at least one block, in the order they appear in the file. It is nil if the file could
+	// not be parsed as Go source code.
+	Functions []AnalyzerFunctionResult
+
 	// UncoveredBlocks are the code blocks in this file that lacked coverage. The list is sorted in
 	// ascending order of starting line number. It does not include any locations that were
 	// skipped with "-skipcode".
 	UncoveredBlocks []UncoveredBlock
 }
 
+// AnalyzerFunctionResult is function-level information in AnalyzerFileResult.
+type AnalyzerFunctionResult struct {
+	// Name is the name of the function. For methods, it is prefixed with the receiver type, as in
+	// "TypeName.MethodName".
+	Name string
+
+	// StartLine is the line number where the function declaration begins.
+	StartLine int
+
+	// TotalStatements is the number of statements in all blocks in this function that were
+	// included in the coverage profile. Function literals are counted as part of the function that
+	// contains them.
+	TotalStatements int
+
+	// CoveredStatements is the number of statements in all blocks in this function that were
+	// reported as covered in the coverage profile.
+	CoveredStatements int
+
+	// Blocks are all of the code blocks in this function, covered or not, in the same order as in
+	// AnalyzerFileResult.Blocks.
+	Blocks []CodeBlockCoverage
+}
+
 // UncoveredBlock is a code range that had no coverage.
 type UncoveredBlock struct {
 	CodeRange
 
+	// StatementCount is the number of statements in the code range.
+	StatementCount int
+
+	// CoverageCount is the number of times the block was executed. It is always zero unless the
+	// "-minhits" option was used, in which case it may be less than the minimum.
+	CoverageCount int
+
+	// FunctionName is the name of the top-level function or method containing the block, in the
+	// same format as AnalyzerFunctionResult.Name. It is empty if the source file could not be parsed
+	// or if the block is not inside a function.
+	FunctionName string
+
 	// Text is an optional excerpt of the source code file corresponding to the range's starting
 	// and ending line numbers. It is only provided if the "-showcode" option was used; otherwise
 	// it is nil.
 	Text []string
+
+	// LeadingContext and TrailingContext are the source lines immediately before and after Text,
+	// as requested with the "-context" option. They may be shorter than requested if the block is
+	// near the beginning or end of the file.
+	LeadingContext  []string
+	TrailingContext []string
+}
+
+// SkippedBlock is a code block that was excluded from the analysis.
+type SkippedBlock struct {
+	CodeBlockCoverage
+
+	// Reason describes which option caused the block to be skipped.
+	Reason SkipReason
+}
+
+// SkipReason describes why a file or code block was skipped.
+type SkipReason struct {
+	// Option is the name of the command-line option that caused the skip: "skipfiles" or
+	// "skipcode".
+	Option string
+
+	// Pattern is the regular expression that was specified for that option.
+	Pattern string
+
+	// Line is the line number of the source line that matched the "-skipcode" pattern. It is
+	// zero for "-skipfiles".
+	Line int
+
+	// Text is the source line that matched the "-skipcode" pattern. It is empty for "-skipfiles".
+	Text string
 }
@@ -3,6 +3,7 @@ package main
 import (
 	"bytes"
 	"regexp"
+	"strings"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -23,13 +24,16 @@ func TestAnalyzeCoverage(t *testing.T) {
 	t.Run("skips files based on file path pattern", func(t *testing.T) {
 		expectedResult := makeTestAnalyzerExpectedResult()
 		expectedResult.SkippedFilePaths = []string{testDataPackagePath + "/second"}
-		expectedResult.SkippedBlocks = []CodeBlockCoverage{
+		expectedResult.SkippedBlocks = []SkippedBlock{
 			{
-				CodeRange: CodeRange{
-					FilePath:  testDataPackagePath + "/second",
-					StartLine: 1, StartColumn: 1, EndLine: 5, EndColumn: 1,
+				CodeBlockCoverage: CodeBlockCoverage{
+					CodeRange: CodeRange{
+						FilePath:  testDataPackagePath + "/second",
+						StartLine: 1, StartColumn: 1, EndLine: 5, EndColumn: 1,
+					},
+					StatementCount: 5, CoverageCount: 0,
 				},
-				StatementCount: 5, CoverageCount: 0,
+				Reason: SkipReason{Option: "skipfiles", Pattern: "econ"},
 			},
 		}
 		expectedResult.Packages[0].Files = []AnalyzerFileResult{
@@ -60,18 +64,22 @@ func TestAnalyzeCoverage(t *testing.T) {
 
 	t.Run("skips blocks based on code pattern", func(t *testing.T) {
 		expectedResult := makeTestAnalyzerExpectedResult()
-		expectedResult.SkippedBlocks = []CodeBlockCoverage{
+		expectedResult.SkippedBlocks = []SkippedBlock{
 			{
-				CodeRange: CodeRange{
-					FilePath:  testDataPackagePath + "/third",
-					StartLine: 1, StartColumn: 1, EndLine: 2, EndColumn: 1,
+				CodeBlockCoverage: CodeBlockCoverage{
+					CodeRange: CodeRange{
+						FilePath:  testDataPackagePath + "/third",
+						StartLine: 1, StartColumn: 1, EndLine: 2, EndColumn: 1,
+					},
+					StatementCount: 2, CoverageCount: 0,
 				},
-				StatementCount: 2, CoverageCount: 0,
+				Reason: SkipReason{Option: "skipcode", Pattern: "third.*1", Line: 1, Text: "third file line 1"},
 			},
 		}
 		expectedResult.Packages[0].Files[2].UncoveredBlocks = []UncoveredBlock{
 			expectedResult.Packages[0].Files[2].UncoveredBlocks[1],
 		}
+		expectedResult.Packages[0].Files[2].Blocks = expectedResult.Packages[0].Files[2].Blocks[1:]
 		expectedResult.Packages[0].Files[2].TotalStatements -= 2
 
 		withValidTestProfile(testDataMainFile, func(cp *CoverageProfile) {
@@ -83,6 +91,22 @@ func TestAnalyzeCoverage(t *testing.T) {
 		})
 	})
 
+	t.Run("files with the same name in different packages", func(t *testing.T) {
+		cp, err := ReadCoverageProfile(strings.NewReader(`mode: set
+base-package/first:1.1,2.1 2 1
+base-package/otherpackage/first:1.1,2.1 3 0
+`))
+		require.NoError(t, err)
+		result, err := AnalyzeCoverage(cp, testBaseOptions)
+		require.NoError(t, err)
+
+		require.Len(t, result.Packages, 2)
+		require.Len(t, result.Packages[0].Files, 1)
+		assert.Equal(t, 2, result.Packages[0].Files[0].TotalStatements)
+		require.Len(t, result.Packages[1].Files, 1)
+		assert.Equal(t, 3, result.Packages[1].Files[0].TotalStatements)
+	})
+
 	t.Run("error for wrong package path", func(t *testing.T) {
 		withValidTestProfile(testDataMainFile, func(cp *CoverageProfile) {
 			opts := EnforcerOptions{PackagePath: "not-" + testDataPackagePath}
@@ -130,3 +154,43 @@ func TestAnalyzerWriteFilteredProfile(t *testing.T) {
 		}, cp1.Blocks)
 	})
 }
+
+func TestAnalyzeCoverageWithContextAtStartOfFile(t *testing.T) {
+	withValidTestProfile(testDataMainFile, func(cp *CoverageProfile) {
+		opts := testBaseOptions
+		opts.ShowCode = true
+		opts.ContextLines = 2
+		result, err := AnalyzeCoverage(cp, opts)
+		require.NoError(t, err)
+
+		b := result.Packages[0].Files[0].UncoveredBlocks[0]
+		assert.Equal(t, 1, b.CodeRange.StartLine)
+		assert.Nil(t, b.LeadingContext)
+		assert.Equal(t, []string{"first file line 1", "first file line 2"}, b.Text)
+		assert.Equal(t, []string{"first file line 3", "first file line 4"}, b.TrailingContext)
+	})
+}
+
+func TestSplitContextLines(t *testing.T) {
+	lines := []string{"a", "b", "c", "d", "e"}
+
+	leading, block, trailing := splitContextLines(lines, 1, 2)
+	assert.Equal(t, []string{"a"}, leading)
+	assert.Equal(t, []string{"b", "c"}, block)
+	assert.Equal(t, []string{"d", "e"}, trailing)
+
+	leading, block, trailing = splitContextLines(lines, 0, 5)
+	assert.Nil(t, leading)
+	assert.Equal(t, lines, block)
+	assert.Nil(t, trailing)
+
+	leading, block, trailing = splitContextLines(lines, 4, 3)
+	assert.Equal(t, []string{"a", "b", "c", "d"}, leading)
+	assert.Equal(t, []string{"e"}, block)
+	assert.Nil(t, trailing)
+
+	leading, block, trailing = splitContextLines(lines, 6, 1)
+	assert.Equal(t, lines, leading)
+	assert.Empty(t, block)
+	assert.Nil(t, trailing)
+}
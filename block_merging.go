@@ -0,0 +1,108 @@
+package main
+
+import (
+	"go/scanner"
+	"go/token"
+	"sort"
+	"strings"
+)
+
+// mergeUncoveredBlocks combines uncovered blocks from a single file into larger blocks, for the
+// "-mergeblocks" option. Two blocks are merged if the source code between them consists only of
+// whitespace and comments; since every block contains at least one statement, this means that no
+// covered or skipped code can be between them. The merged block's statement count is the sum of
+// the statement counts of the original blocks.
+//
+// The source parameter is the content of the file. If it is nil, because the file could not be
+// read, only blocks that are exactly adjacent to each other are merged.
+func mergeUncoveredBlocks(blocks []UncoveredBlock, source []byte) []UncoveredBlock {
+	if len(blocks) < 2 {
+		return blocks
+	}
+	sorted := append([]UncoveredBlock(nil), blocks...)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		a, b := sorted[i].CodeRange, sorted[j].CodeRange
+		return a.StartLine < b.StartLine || (a.StartLine == b.StartLine && a.StartColumn < b.StartColumn)
+	})
+
+	lineOffsets := getLineOffsets(source)
+	var sourceLines []string
+	if source != nil {
+		sourceLines = strings.Split(string(source), "\n")
+	}
+
+	ret := make([]UncoveredBlock, 0, len(sorted))
+	for _, b := range sorted {
+		if n := len(ret); n > 0 && canMergeBlocks(ret[n-1].CodeRange, b.CodeRange, source, lineOffsets) {
+			ret[n-1] = mergeTwoBlocks(ret[n-1], b, sourceLines)
+			continue
+		}
+		ret = append(ret, b)
+	}
+	return ret
+}
+
+func canMergeBlocks(a, b CodeRange, source []byte, lineOffsets []int) bool {
+	if a.EndLine == b.StartLine && a.EndColumn == b.StartColumn {
+		return true
+	}
+	from, ok1 := getSourceOffset(lineOffsets, len(source), a.EndLine, a.EndColumn)
+	to, ok2 := getSourceOffset(lineOffsets, len(source), b.StartLine, b.StartColumn)
+	if !ok1 || !ok2 || to < from {
+		return false
+	}
+	return isOnlyWhitespaceAndComments(source[from:to])
+}
+
+func mergeTwoBlocks(a, b UncoveredBlock, sourceLines []string) UncoveredBlock {
+	merged := a
+	merged.CodeRange.EndLine, merged.CodeRange.EndColumn = b.CodeRange.EndLine, b.CodeRange.EndColumn
+	merged.StatementCount += b.StatementCount
+	if b.FunctionName != a.FunctionName {
+		merged.FunctionName = ""
+	}
+	if a.Text != nil && merged.CodeRange.EndLine <= len(sourceLines) {
+		merged.Text = append([]string(nil), sourceLines[merged.CodeRange.StartLine-1:merged.CodeRange.EndLine]...)
+	}
+	merged.TrailingContext = b.TrailingContext
+	return merged
+}
+
+// getLineOffsets returns the byte offset of the start of each line in the source code.
+func getLineOffsets(source []byte) []int {
+	if source == nil {
+		return nil
+	}
+	offsets := []int{0}
+	for i, ch := range source {
+		if ch == '\n' {
+			offsets = append(offsets, i+1)
+		}
+	}
+	return offsets
+}
+
+func getSourceOffset(lineOffsets []int, sourceLength, line, column int) (int, bool) {
+	if line < 1 || line > len(lineOffsets) || column < 1 {
+		return 0, false
+	}
+	offset := lineOffsets[line-1] + column - 1
+	return offset, offset <= sourceLength
+}
+
+func isOnlyWhitespaceAndComments(text []byte) bool {
+	fset := token.NewFileSet()
+	file := fset.AddFile("", fset.Base(), len(text))
+	var s scanner.Scanner
+	s.Init(file, text, nil, scanner.ScanComments)
+	for {
+		_, tok, _ := s.Scan()
+		switch {
+		case tok == token.EOF:
+			return s.ErrorCount == 0
+		case tok == token.COMMENT:
+		default:
+			return false
+		}
+	}
+}
@@ -0,0 +1,104 @@
+package main
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestIsOnlyWhitespaceAndComments(t *testing.T) {
+	assert.True(t, isOnlyWhitespaceAndComments([]byte("")))
+	assert.True(t, isOnlyWhitespaceAndComments([]byte("\n\t  \n")))
+	assert.True(t, isOnlyWhitespaceAndComments([]byte("\n\t// a comment\n\t")))
+	assert.True(t, isOnlyWhitespaceAndComments([]byte(" /* a\nblock comment */ ")))
+	assert.False(t, isOnlyWhitespaceAndComments([]byte("\n\tx := 1\n")))
+	assert.False(t, isOnlyWhitespaceAndComments([]byte("}\n\nfunc f() ")))
+	assert.False(t, isOnlyWhitespaceAndComments([]byte("/* unterminated")))
+}
+
+func TestMergeUncoveredBlocks(t *testing.T) {
+	source := []byte("func f() {\n\ta()\n\n\t// comment\n\tb()\n\tc()\n}\n")
+	makeBlock := func(startLine, startCol, endLine, endCol, statements int, funcName string) UncoveredBlock {
+		return UncoveredBlock{
+			CodeRange:      CodeRange{FilePath: "x", StartLine: startLine, StartColumn: startCol, EndLine: endLine, EndColumn: endCol},
+			StatementCount: statements,
+			FunctionName:   funcName,
+		}
+	}
+
+	t.Run("single block is unchanged", func(t *testing.T) {
+		blocks := []UncoveredBlock{makeBlock(2, 2, 2, 5, 1, "f")}
+		assert.Equal(t, blocks, mergeUncoveredBlocks(blocks, source))
+	})
+
+	t.Run("blocks separated by whitespace and comments are merged", func(t *testing.T) {
+		blocks := []UncoveredBlock{makeBlock(5, 2, 5, 5, 1, "f"), makeBlock(2, 2, 2, 5, 1, "f")}
+		assert.Equal(t, []UncoveredBlock{makeBlock(2, 2, 5, 5, 2, "f")}, mergeUncoveredBlocks(blocks, source))
+	})
+
+	t.Run("blocks separated by code are not merged", func(t *testing.T) {
+		blocks := []UncoveredBlock{makeBlock(2, 2, 2, 5, 1, "f"), makeBlock(6, 2, 6, 5, 1, "f")}
+		assert.Equal(t, blocks, mergeUncoveredBlocks(blocks, source))
+	})
+
+	t.Run("adjacent blocks are merged even without source", func(t *testing.T) {
+		blocks := []UncoveredBlock{makeBlock(2, 2, 2, 5, 1, "f"), makeBlock(2, 5, 3, 1, 2, "g")}
+		assert.Equal(t, []UncoveredBlock{makeBlock(2, 2, 3, 1, 3, "")}, mergeUncoveredBlocks(blocks, nil))
+	})
+
+	t.Run("non-adjacent blocks are not merged without source", func(t *testing.T) {
+		blocks := []UncoveredBlock{makeBlock(2, 2, 2, 5, 1, "f"), makeBlock(5, 2, 5, 5, 1, "f")}
+		assert.Equal(t, blocks, mergeUncoveredBlocks(blocks, nil))
+	})
+
+	t.Run("source text is replaced for merged block", func(t *testing.T) {
+		b1, b2 := makeBlock(2, 2, 2, 5, 1, "f"), makeBlock(5, 2, 5, 5, 1, "f")
+		b1.Text, b1.LeadingContext = []string{"\ta()"}, []string{"func f() {"}
+		b2.Text, b2.TrailingContext = []string{"\tb()"}, []string{"\tc()"}
+		merged := mergeUncoveredBlocks([]UncoveredBlock{b1, b2}, source)
+		require.Len(t, merged, 1)
+		assert.Equal(t, []string{"\ta()", "", "\t// comment", "\tb()"}, merged[0].Text)
+		assert.Equal(t, []string{"func f() {"}, merged[0].LeadingContext)
+		assert.Equal(t, []string{"\tc()"}, merged[0].TrailingContext)
+	})
+}
+
+func TestReportOutputWithMergedBlocks(t *testing.T) {
+	withValidTestProfile(testDataFuncsFile, func(cp *CoverageProfile) {
+		opts := testBaseOptions
+		opts.MergeBlocks = true
+		result, err := AnalyzeCoverage(cp, opts)
+		require.NoError(t, err)
+		report := NewSummaryReport(result, opts)
+
+		assert.Equal(t, []SummaryReportRule{
+			{Name: uncoveredBlocksRuleName, Pass: false, Message: "2 uncovered block(s) detected"},
+		}, report.Rules)
+
+		buf := new(bytes.Buffer)
+		report.Output(buf, opts)
+		assert.Equal(t, `Uncovered blocks detected:
+base-package/gosource/sample.go 10-12 (1 statement in T.Inc)
+base-package/gosource/sample.go 16-21 (3 statements in T.Get)
+`, buf.String())
+	})
+}
+
+func TestReportOutputWithMergedBlocksOutsideFunctions(t *testing.T) {
+	withValidTestProfile(testDataReportNotPassFile, func(cp *CoverageProfile) {
+		opts := testBaseOptions
+		opts.MergeBlocks = true
+		result, err := AnalyzeCoverage(cp, opts)
+		require.NoError(t, err)
+		report := NewSummaryReport(result, opts)
+
+		buf := new(bytes.Buffer)
+		report.Output(buf, opts)
+		assert.Equal(t, `Uncovered blocks detected:
+base-package/first 3-4 (2 statements)
+base-package/otherpackage/first 1-3 (7 statements)
+`, buf.String())
+	})
+}
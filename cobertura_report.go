@@ -0,0 +1,112 @@
+package main
+
+import (
+	"encoding/xml"
+	"io"
+	"path"
+	"time"
+)
+
+const coberturaDocType = `<!DOCTYPE coverage SYSTEM "http://cobertura.sourceforge.net/xml/coverage-04.dtd">`
+
+type coberturaCoverage struct {
+	XMLName         xml.Name           `xml:"coverage"`
+	LineRate        float64            `xml:"line-rate,attr"`
+	BranchRate      float64            `xml:"branch-rate,attr"`
+	LinesCovered    int                `xml:"lines-covered,attr"`
+	LinesValid      int                `xml:"lines-valid,attr"`
+	BranchesCovered int                `xml:"branches-covered,attr"`
+	BranchesValid   int                `xml:"branches-valid,attr"`
+	Complexity      float64            `xml:"complexity,attr"`
+	Version         string             `xml:"version,attr"`
+	Timestamp       int64              `xml:"timestamp,attr"`
+	Sources         []string           `xml:"sources>source"`
+	Packages        []coberturaPackage `xml:"packages>package"`
+}
+
+type coberturaPackage struct {
+	Name       string           `xml:"name,attr"`
+	LineRate   float64          `xml:"line-rate,attr"`
+	BranchRate float64          `xml:"branch-rate,attr"`
+	Complexity float64          `xml:"complexity,attr"`
+	Classes    []coberturaClass `xml:"classes>class"`
+}
+
+type coberturaClass struct {
+	Name       string          `xml:"name,attr"`
+	Filename   string          `xml:"filename,attr"`
+	LineRate   float64         `xml:"line-rate,attr"`
+	BranchRate float64         `xml:"branch-rate,attr"`
+	Complexity float64         `xml:"complexity,attr"`
+	Methods    string          `xml:"methods"`
+	Lines      []coberturaLine `xml:"lines>line"`
+}
+
+type coberturaLine struct {
+	Number int `xml:"number,attr"`
+	Hits   int `xml:"hits,attr"`
+}
+
+// WriteCoberturaReport writes the report data as Cobertura XML. Each package becomes a Cobertura
+// package and each file becomes a class.
+//
+// The line elements are computed from the code ranges of the blocks as described for
+// getLineHitCounts; lines that are only part of skipped blocks are omitted, and lines whose count
+// is less than "-minhits" are reported as having no hits. The line-rate attributes are the ratios
+// of covered lines to total lines, so they are the same as the line coverage that is computed for
+// the other reports. Branch coverage is not available from Go coverage profiles, so it is always
+// reported as zero.
+func WriteCoberturaReport(writer io.Writer, report SummaryReport, result AnalyzerResult, opts EnforcerOptions) error {
+	total := report.GetTotalCoverage()
+	doc := coberturaCoverage{
+		LineRate:     getCoberturaRate(total),
+		LinesCovered: total.CoveredLines,
+		LinesValid:   total.TotalLines,
+		Timestamp:    time.Now().UnixNano() / int64(time.Millisecond),
+		Sources:      []string{"."},
+	}
+
+	minHits := getMinHits(opts)
+	packageResults := getPackageResultsByPath(result, opts)
+	for _, rp := range report.Packages {
+		p := packageResults[rp.FullPackagePath]
+		cp := coberturaPackage{
+			Name:     rp.FullPackagePath,
+			LineRate: getCoberturaRate(rp.Coverage),
+		}
+		for j, f := range p.Files {
+			class := coberturaClass{
+				Name:     f.FileName,
+				Filename: path.Join(p.RelativePath, f.FileName),
+				LineRate: getCoberturaRate(rp.Files[j].Coverage),
+			}
+			for _, lh := range getLineHitCounts(f.Blocks) {
+				hits := lh.Hits
+				if !isCovered(hits, minHits) {
+					hits = 0
+				}
+				class.Lines = append(class.Lines, coberturaLine{Number: lh.Line, Hits: hits})
+			}
+			cp.Classes = append(cp.Classes, class)
+		}
+		doc.Packages = append(doc.Packages, cp)
+	}
+
+	if _, err := io.WriteString(writer, xml.Header+coberturaDocType+"\n"); err != nil {
+		return err
+	}
+	enc := xml.NewEncoder(writer)
+	enc.Indent("", "  ")
+	if err := enc.Encode(doc); err != nil {
+		return err
+	}
+	_, err := io.WriteString(writer, "\n")
+	return err
+}
+
+func getCoberturaRate(c SummaryReportCoverage) float64 {
+	if c.TotalLines == 0 {
+		return 1
+	}
+	return float64(c.CoveredLines) / float64(c.TotalLines)
+}
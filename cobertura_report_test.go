@@ -0,0 +1,120 @@
+package main
+
+import (
+	"bytes"
+	"encoding/xml"
+	"regexp"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCoberturaReport(t *testing.T) {
+	t.Run("report from data with coverage gaps", func(t *testing.T) {
+		withValidTestProfile(testDataReportNotPassFile, func(cp *CoverageProfile) {
+			s, doc := writeAndParseCoberturaReport(t, cp, testBaseOptions)
+
+			assert.Contains(t, s, coberturaDocType)
+			assert.Equal(t, 0.75, doc.LineRate)
+			assert.Equal(t, 9, doc.LinesCovered)
+			assert.Equal(t, 12, doc.LinesValid)
+			assert.Equal(t, float64(doc.LinesCovered)/float64(doc.LinesValid), doc.LineRate)
+			assert.NotZero(t, doc.Timestamp)
+			assert.Equal(t, []string{"."}, doc.Sources)
+
+			require.Len(t, doc.Packages, 2)
+			p1 := doc.Packages[0]
+			assert.Equal(t, testDataPackagePath, p1.Name)
+			assert.Equal(t, 0.9, p1.LineRate)
+			require.Len(t, p1.Classes, 3)
+			assert.Equal(t, coberturaClass{
+				Name:     "first",
+				Filename: "first",
+				LineRate: 0.5,
+				Lines:    []coberturaLine{{Number: 1, Hits: 1}, {Number: 3, Hits: 0}},
+			}, p1.Classes[0])
+			assert.Equal(t, coberturaClass{
+				Name:     "second",
+				Filename: "second",
+				LineRate: 1,
+				Lines: []coberturaLine{
+					{Number: 1, Hits: 1}, {Number: 2, Hits: 1}, {Number: 3, Hits: 1}, {Number: 4, Hits: 1},
+				},
+			}, p1.Classes[1])
+
+			p2 := doc.Packages[1]
+			assert.Equal(t, testDataPackagePath+"/otherpackage", p2.Name)
+			assert.Equal(t, 0.0, p2.LineRate)
+			require.Len(t, p2.Classes, 1)
+			assert.Equal(t, "otherpackage/first", p2.Classes[0].Filename)
+			assert.Equal(t, []coberturaLine{{Number: 1, Hits: 0}, {Number: 2, Hits: 0}}, p2.Classes[0].Lines)
+		})
+	})
+
+	t.Run("skipped blocks are omitted", func(t *testing.T) {
+		withValidTestProfile(testDataMainFile, func(cp *CoverageProfile) {
+			opts := testBaseOptions
+			opts.SkipFilesPattern = regexp.MustCompile("econ")
+			opts.SkipCodePattern = regexp.MustCompile("third.*1")
+			_, doc := writeAndParseCoberturaReport(t, cp, opts)
+
+			require.Len(t, doc.Packages, 2)
+			p1 := doc.Packages[0]
+			require.Len(t, p1.Classes, 2)
+			assert.Equal(t, "third", p1.Classes[1].Name)
+			assert.Equal(t, 0.5, p1.Classes[1].LineRate)
+			assert.Equal(t, []coberturaLine{{Number: 3, Hits: 1}, {Number: 4, Hits: 0}}, p1.Classes[1].Lines)
+		})
+	})
+
+	t.Run("rate is 1 if there are no lines", func(t *testing.T) {
+		buf := new(bytes.Buffer)
+		require.NoError(t, WriteReport(buf, "cobertura", SummaryReport{}, AnalyzerResult{}, testBaseOptions))
+		var doc coberturaCoverage
+		require.NoError(t, xml.Unmarshal(buf.Bytes(), &doc))
+		assert.Equal(t, 1.0, doc.LineRate)
+		assert.Equal(t, 0, doc.LinesValid)
+	})
+}
+
+func TestCoberturaReportWithMinHits(t *testing.T) {
+	withValidTestProfile(testDataCountsFile, func(cp *CoverageProfile) {
+		opts := testBaseOptions
+		opts.MinHits = 3
+		_, doc := writeAndParseCoberturaReport(t, cp, opts)
+
+		require.Len(t, doc.Packages, 1)
+		require.Len(t, doc.Packages[0].Classes, 1)
+		assert.Equal(t, []coberturaLine{
+			{Number: 3, Hits: 150}, {Number: 4, Hits: 150}, {Number: 5, Hits: 150},
+			{Number: 9, Hits: 5}, {Number: 10, Hits: 0}, {Number: 11, Hits: 0}, {Number: 12, Hits: 0},
+			{Number: 13, Hits: 4}, {Number: 14, Hits: 4},
+			{Number: 16, Hits: 0}, {Number: 17, Hits: 0}, {Number: 18, Hits: 0}, {Number: 19, Hits: 0},
+			{Number: 20, Hits: 0}, {Number: 21, Hits: 0},
+		}, doc.Packages[0].Classes[0].Lines)
+		assert.Equal(t, 6, doc.LinesCovered)
+		assert.Equal(t, 15, doc.LinesValid)
+		assert.Equal(t, 0.4, doc.LineRate)
+	})
+}
+
+func TestCoberturaReportWriteError(t *testing.T) {
+	withValidTestProfile(testDataReportNotPassFile, func(cp *CoverageProfile) {
+		result, err := AnalyzeCoverage(cp, testBaseOptions)
+		require.NoError(t, err)
+		report := NewSummaryReport(result, testBaseOptions)
+
+		for _, n := range []int{0, len(xml.Header + coberturaDocType + "\n"), 1000} {
+			err := WriteReport(&failingWriter{remaining: n}, "cobertura", report, result, testBaseOptions)
+			assert.Error(t, err, "write failing after %d bytes", n)
+		}
+	})
+}
+
+func writeAndParseCoberturaReport(t *testing.T, cp *CoverageProfile, opts EnforcerOptions) (string, coberturaCoverage) {
+	s := writeReportForTest(t, cp, opts, "cobertura")
+	var doc coberturaCoverage
+	require.NoError(t, xml.Unmarshal([]byte(s), &doc))
+	return s, doc
+}
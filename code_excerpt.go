@@ -0,0 +1,102 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"unicode/utf8"
+)
+
+// getColumnSpan returns the starting column and the exclusive ending column of the part of a
+// source line that is within the code range. The line must be between StartLine and EndLine.
+// Columns are 1-based byte offsets, as in the coverage profile.
+func (r CodeRange) getColumnSpan(lineNum int, line string) (int, int) {
+	start, end := 1, len(line)+1
+	if lineNum == r.StartLine {
+		start = r.StartColumn
+	}
+	if lineNum == r.EndLine && r.EndColumn < end {
+		end = r.EndColumn
+	}
+	return start, end
+}
+
+// splitLineAtColumns divides a source line into the text before, within, and after the given
+// columns, not including any whitespace at either end of the columns in the middle part. The
+// columns are clamped to the length of the line.
+func splitLineAtColumns(line string, start, end int) (before, within, after string) {
+	if start < 1 {
+		start = 1
+	}
+	if end > len(line)+1 {
+		end = len(line) + 1
+	}
+	for start < end && isSpaceOrTab(line[start-1]) {
+		start++
+	}
+	for start < end && isSpaceOrTab(line[end-2]) {
+		end--
+	}
+	if start >= end {
+		return line, "", ""
+	}
+	return line[:start-1], line[start-1 : end-1], line[end-1:]
+}
+
+// makeCaretLine returns a line of "^" characters that underlines the given columns of a source
+// line, not including any whitespace at either end of the columns. Tabs in the source line are
+// preserved so that the carets line up with the text when both lines are displayed with the same
+// tab stops. The result is an empty string if there is nothing to underline, or if the columns
+// include all of the non-whitespace text on the line, since in that case the underline would not
+// tell the reader anything.
+func makeCaretLine(line string, start, end int) string {
+	before, within, after := splitLineAtColumns(line, start, end)
+	if within == "" || (strings.TrimSpace(before) == "" && strings.TrimSpace(after) == "") {
+		return ""
+	}
+
+	var sb strings.Builder
+	for _, ch := range before {
+		if ch == '\t' {
+			sb.WriteRune('\t')
+		} else {
+			sb.WriteRune(' ')
+		}
+	}
+	sb.WriteString(strings.Repeat("^", utf8.RuneCountInString(within)))
+	return sb.String()
+}
+
+// writeCodeExcerpt writes the source code of an uncovered block, with line numbers, for the
+// "-showcode" option. Lines of the block are marked with ">", and each of them that is only
+// partly within the block is followed by a line of carets underlining the uncovered columns; if
+// color is enabled, the uncovered columns are highlighted instead. Any lines of context requested
+// with "-context" are marked with a space instead of ">".
+func writeCodeExcerpt(writer io.Writer, b UncoveredBlock, opts EnforcerOptions) {
+	lineNum := b.CodeRange.StartLine - len(b.LeadingContext)
+	for _, line := range b.LeadingContext {
+		fmt.Fprintf(writer, "%d \t%s\n", lineNum, line)
+		lineNum++
+	}
+	for _, line := range b.Text {
+		start, end := b.CodeRange.getColumnSpan(lineNum, line)
+		if opts.UseColor {
+			before, within, after := splitLineAtColumns(line, start, end)
+			fmt.Fprintf(writer, "%d>\t%s%s%s\n", lineNum, before, colorize(within, ansiRed, opts), after)
+		} else {
+			fmt.Fprintf(writer, "%d>\t%s\n", lineNum, line)
+			if carets := makeCaretLine(line, start, end); carets != "" {
+				fmt.Fprintf(writer, "\t%s\n", carets)
+			}
+		}
+		lineNum++
+	}
+	for _, line := range b.TrailingContext {
+		fmt.Fprintf(writer, "%d \t%s\n", lineNum, line)
+		lineNum++
+	}
+}
+
+func isSpaceOrTab(ch byte) bool {
+	return ch == ' ' || ch == '\t'
+}
@@ -0,0 +1,106 @@
+package main
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGetColumnSpan(t *testing.T) {
+	r := CodeRange{StartLine: 2, StartColumn: 5, EndLine: 4, EndColumn: 3}
+	line := "abcdefgh"
+
+	start, end := r.getColumnSpan(2, line)
+	assert.Equal(t, []int{5, 9}, []int{start, end})
+	start, end = r.getColumnSpan(3, line)
+	assert.Equal(t, []int{1, 9}, []int{start, end})
+	start, end = r.getColumnSpan(4, line)
+	assert.Equal(t, []int{1, 3}, []int{start, end})
+}
+
+func TestSplitLineAtColumns(t *testing.T) {
+	before, within, after := splitLineAtColumns("\tif x {", 5, 7)
+	assert.Equal(t, []string{"\tif ", "x", " {"}, []string{before, within, after})
+
+	before, within, after = splitLineAtColumns("abc", 0, 3)
+	assert.Equal(t, []string{"", "ab", "c"}, []string{before, within, after}, "start before beginning of line")
+
+	before, within, after = splitLineAtColumns("abc", 2, 10)
+	assert.Equal(t, []string{"a", "bc", ""}, []string{before, within, after}, "end past end of line")
+}
+
+func TestMakeCaretLine(t *testing.T) {
+	assert.Equal(t, "\t     ^", makeCaretLine("\tif x {", 7, 8))
+	assert.Equal(t, "\t^^^^", makeCaretLine("\tif x {", 1, 6))
+	assert.Equal(t, "  ^^^^^^^^", makeCaretLine("a := b + c", 3, 11))
+	assert.Equal(t, "  ^^^", makeCaretLine("é = ü", 4, 8), "columns are bytes but carets are characters")
+	assert.Equal(t, "", makeCaretLine("\tx++", 1, 5), "whole line")
+	assert.Equal(t, "", makeCaretLine("\tx++", 2, 100), "whole line with end past end of line")
+	assert.Equal(t, "", makeCaretLine("\t}", 1, 1), "empty span")
+	assert.Equal(t, "", makeCaretLine("x   y", 2, 5), "only whitespace")
+	assert.Equal(t, "", makeCaretLine("", 1, 1), "empty line")
+}
+
+func TestWriteCodeExcerpt(t *testing.T) {
+	b := UncoveredBlock{
+		CodeRange:       CodeRange{StartLine: 3, StartColumn: 8, EndLine: 4, EndColumn: 5},
+		Text:            []string{"\tif x { y()", "\t}; z()"},
+		LeadingContext:  []string{"func f() {"},
+		TrailingContext: []string{"}"},
+	}
+	buf := new(bytes.Buffer)
+	writeCodeExcerpt(buf, b, EnforcerOptions{})
+	assert.Equal(t, "2 \tfunc f() {\n"+
+		"3>\t\tif x { y()\n"+
+		"\t\t       ^^^\n"+
+		"4>\t\t}; z()\n"+
+		"\t\t^^\n"+
+		"5 \t}\n", buf.String())
+}
+
+func TestReportOutputWithCodeExcerpts(t *testing.T) {
+	withValidTestProfile(testDataFuncsFile, func(cp *CoverageProfile) {
+		opts := testBaseOptions
+		opts.ShowCode = true
+		opts.ContextLines = 1
+		result, err := AnalyzeCoverage(cp, opts)
+		require.NoError(t, err)
+		report := NewSummaryReport(result, opts)
+
+		buf := new(bytes.Buffer)
+		report.Output(buf, opts)
+		assert.Equal(t, `Uncovered blocks detected:
+
+base-package/gosource/sample.go 10-12 (in T.Inc)
+9 	func (t *T) Inc(by int) {
+10>		if by < 0 {
+		          ^
+11>			panic("negative")
+12>		}
+13 		t.n += by
+
+base-package/gosource/sample.go 16-17 (in T.Get)
+15 	
+16>	func (t T) Get() int {
+	                     ^
+17>		f := func() int {
+		^^^^^^^^^^^^^^^
+18 			return t.n
+
+base-package/gosource/sample.go 17-19 (in T.Get)
+16 	func (t T) Get() int {
+17>		f := func() int {
+		                ^
+18>			return t.n
+19>		}
+20 		return f()
+
+base-package/gosource/sample.go 20-21 (in T.Get)
+19 		}
+20>		return f()
+21>	}
+`, buf.String())
+	})
+}
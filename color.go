@@ -0,0 +1,88 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"strings"
+)
+
+// These are the allowable values for the "-color" option.
+const (
+	colorModeAuto   = "auto"
+	colorModeAlways = "always"
+	colorModeNever  = "never"
+)
+
+var colorModes = []string{colorModeAuto, colorModeAlways, colorModeNever}
+
+// These ANSI escape sequences all have the same length, so that a column of a table in which every
+// cell is colored with one of them will still be aligned correctly by tabwriter.
+const (
+	ansiRed    = "\x1b[31m"
+	ansiGreen  = "\x1b[32m"
+	ansiYellow = "\x1b[33m"
+	ansiReset  = "\x1b[0m"
+)
+
+// goodCoveragePercent is the lowest coverage percentage that is shown in yellow rather than red.
+// Only 100% coverage is shown in green.
+const goodCoveragePercent = 80
+
+func getColorModeNames() string {
+	return strings.Join(colorModes, ", ")
+}
+
+func isValidColorMode(s string) bool {
+	for _, m := range colorModes {
+		if s == m {
+			return true
+		}
+	}
+	return false
+}
+
+// shouldUseColor determines whether output to the specified file should be colorized, as
+// specified by the "-color" option. In auto mode, color is only used if the file is a terminal
+// and the NO_COLOR environment variable is not set (see https://no-color.org).
+func shouldUseColor(colorMode string, f *os.File) bool {
+	switch colorMode {
+	case colorModeAlways:
+		return true
+	case colorModeNever:
+		return false
+	default:
+		return os.Getenv("NO_COLOR") == "" && isTerminal(f)
+	}
+}
+
+func isTerminal(f *os.File) bool {
+	info, err := f.Stat()
+	return err == nil && info.Mode()&os.ModeCharDevice != 0
+}
+
+// colorize wraps the string in the specified ANSI color sequence, if color is enabled.
+func colorize(s, color string, opts EnforcerOptions) string {
+	if !opts.UseColor || s == "" {
+		return s
+	}
+	return color + s + ansiReset
+}
+
+func getCoverageColor(percent int) string {
+	switch {
+	case percent == 100:
+		return ansiGreen
+	case percent >= goodCoveragePercent:
+		return ansiYellow
+	default:
+		return ansiRed
+	}
+}
+
+// formatPercentCell returns the coverage percentage for the metric selected with "-metric" in
+// parentheses, as shown in the stats tables, colored according to the percentage if color is
+// enabled.
+func formatPercentCell(c SummaryReportCoverage, opts EnforcerOptions) string {
+	percent := c.GetMetricPercent(opts.Metric)
+	return colorize(fmt.Sprintf("(%d%%)", percent), getCoverageColor(percent), opts)
+}
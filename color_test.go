@@ -0,0 +1,77 @@
+package main
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestShouldUseColor(t *testing.T) {
+	f, err := ioutil.TempFile("", "color-test")
+	require.NoError(t, err)
+	defer os.Remove(f.Name())
+	defer f.Close()
+
+	assert.True(t, shouldUseColor(colorModeAlways, f))
+	assert.False(t, shouldUseColor(colorModeNever, f))
+	assert.False(t, shouldUseColor(colorModeAuto, f), "a regular file is not a terminal")
+}
+
+func TestColorize(t *testing.T) {
+	assert.Equal(t, "abc", colorize("abc", ansiRed, EnforcerOptions{}))
+	assert.Equal(t, "\x1b[31mabc\x1b[0m", colorize("abc", ansiRed, EnforcerOptions{UseColor: true}))
+	assert.Equal(t, "", colorize("", ansiRed, EnforcerOptions{UseColor: true}))
+}
+
+func TestFormatPercentCell(t *testing.T) {
+	opts := EnforcerOptions{UseColor: true}
+	assert.Equal(t, "\x1b[32m(100%)\x1b[0m", formatPercentCell(SummaryReportCoverage{TotalStatements: 4, CoveredStatements: 4}, opts))
+	assert.Equal(t, "\x1b[32m(100%)\x1b[0m", formatPercentCell(SummaryReportCoverage{}, opts))
+	assert.Equal(t, "\x1b[33m(80%)\x1b[0m", formatPercentCell(SummaryReportCoverage{TotalStatements: 5, CoveredStatements: 4}, opts))
+	assert.Equal(t, "\x1b[31m(75%)\x1b[0m", formatPercentCell(SummaryReportCoverage{TotalStatements: 4, CoveredStatements: 3}, opts))
+	assert.Equal(t, "(75%)", formatPercentCell(SummaryReportCoverage{TotalStatements: 4, CoveredStatements: 3}, EnforcerOptions{}))
+}
+
+func TestReportOutputWithColor(t *testing.T) {
+	t.Run("failing report", func(t *testing.T) {
+		withValidTestProfile(testDataFuncsFile, func(cp *CoverageProfile) {
+			opts := testBaseOptions
+			opts.UseColor = true
+			opts.ShowPackageStats = true
+			opts.ShowCode = true
+			result, err := AnalyzeCoverage(cp, opts)
+			require.NoError(t, err)
+			report := NewSummaryReport(result, opts)
+			report.UncoveredBlocks = report.UncoveredBlocks[:1]
+
+			buf := new(bytes.Buffer)
+			report.Output(buf, opts)
+			assert.Equal(t, "base-package/gosource 3/7 \x1b[31m(42%)\x1b[0m \n"+
+				"\n"+
+				"\x1b[31mUncovered blocks detected:\x1b[0m\n"+
+				"\n"+
+				"base-package/gosource/sample.go 10-12 (in T.Inc)\n"+
+				"10>\t\tif by < 0 \x1b[31m{\x1b[0m\n"+
+				"11>\t\t\t\x1b[31mpanic(\"negative\")\x1b[0m\n"+
+				"12>\t\t\x1b[31m}\x1b[0m\n", buf.String())
+		})
+	})
+
+	t.Run("passing report", func(t *testing.T) {
+		withValidTestProfile(testDataReportPassFile, func(cp *CoverageProfile) {
+			opts := testBaseOptions
+			opts.UseColor = true
+			result, err := AnalyzeCoverage(cp, opts)
+			require.NoError(t, err)
+			report := NewSummaryReport(result, opts)
+
+			buf := new(bytes.Buffer)
+			report.Output(buf, opts)
+			assert.Equal(t, "\x1b[32mCoverage scan passes!\x1b[0m\n", buf.String())
+		})
+	})
+}
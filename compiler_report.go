@@ -0,0 +1,31 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+)
+
+// WriteCompilerReport writes each uncovered block on a line in the same "file:line:column: message"
+// format that the Go compiler uses for errors, so that editors and tools that understand compiler
+// output (such as Vim's quickfix list or Emacs compilation mode) can jump to the block. File paths are
+// relative to the base package directory.
+func WriteCompilerReport(writer io.Writer, report SummaryReport, result AnalyzerResult, opts EnforcerOptions) error {
+	bw := bufio.NewWriter(writer)
+	for _, b := range report.UncoveredBlocks {
+		fmt.Fprintf(bw, "%s:%d:%d: uncovered block (%s)\n",
+			b.CodeRange.GetRelativeFilePath(opts.PackagePath),
+			b.CodeRange.StartLine,
+			b.CodeRange.StartColumn,
+			describeStatementCount(b.StatementCount),
+		)
+	}
+	return bw.Flush()
+}
+
+func describeStatementCount(n int) string {
+	if n == 1 {
+		return "1 statement"
+	}
+	return fmt.Sprintf("%d statements", n)
+}
@@ -0,0 +1,27 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCompilerReport(t *testing.T) {
+	t.Run("report from data with coverage gaps", func(t *testing.T) {
+		withValidTestProfile(testDataMainFile, func(cp *CoverageProfile) {
+			assert.Equal(t, `first:1:1: uncovered block (2 statements)
+first:3:4: uncovered block (1 statement)
+second:1:1: uncovered block (5 statements)
+third:1:1: uncovered block (2 statements)
+third:4:1: uncovered block (2 statements)
+otherpackage/first:2:1: uncovered block (1 statement)
+`, writeReportForTest(t, cp, testBaseOptions, "compiler"))
+		})
+	})
+
+	t.Run("report from data with no coverage gaps", func(t *testing.T) {
+		withValidTestProfile(testDataReportPassFile, func(cp *CoverageProfile) {
+			assert.Equal(t, "", writeReportForTest(t, cp, testBaseOptions, "compiler"))
+		})
+	})
+}
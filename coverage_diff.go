@@ -0,0 +1,279 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"io/ioutil"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+	"text/tabwriter"
+)
+
+// CoverageDiff is the result returned by DiffCoverage.
+type CoverageDiff struct {
+	// OldCoverage and NewCoverage are the total coverage of all packages in each profile, after
+	// filtering.
+	OldCoverage, NewCoverage SummaryReportCoverage
+
+	// Packages contains the coverage of each package that appeared in either profile, sorted by
+	// import path.
+	Packages []CoverageDiffPackage
+
+	// NewlyUncoveredBlocks are the blocks that were covered in the old profile but not in the new
+	// one, in the order they appear in the new profile.
+	NewlyUncoveredBlocks []CoverageDiffBlock
+
+	// NewlyCoveredBlocks are the blocks that were not covered in the old profile but are covered in
+	// the new one, in the order they appear in the new profile.
+	NewlyCoveredBlocks []CoverageDiffBlock
+}
+
+// CoverageDiffPackage is package-level information in CoverageDiff.
+type CoverageDiffPackage struct {
+	// Path is the full import path of the package.
+	Path string
+
+	// Old and New are the coverage of the package in each profile. Either is nil if the package did
+	// not appear in that profile.
+	Old, New *SummaryReportCoverage
+
+	// Files contains the coverage of each file that appeared in either profile, sorted by name.
+	Files []CoverageDiffFile
+}
+
+// CoverageDiffFile is file-level information in CoverageDiff.
+type CoverageDiffFile struct {
+	// FileName is the simple filename, without the package path.
+	FileName string
+
+	// Old and New are the coverage of the file in each profile. Either is nil if the file did not
+	// appear in that profile.
+	Old, New *SummaryReportCoverage
+}
+
+// CoverageDiffBlock is a block whose coverage changed between the old and new profiles.
+type CoverageDiffBlock struct {
+	// OldRange and NewRange are the locations of the block in each version of the source code.
+	OldRange, NewRange CodeRange
+
+	// StatementCount is the number of statements in the block in the new profile.
+	StatementCount int
+}
+
+// DiffCoverage compares two coverage profiles after applying the same filtering options to each.
+// Source files for the old profile are read from opts.OldSourceDir, or from the current directory
+// if that is empty.
+//
+// Blocks in the two profiles are matched by their file path and their source code, ignoring
+// differences in whitespace, so that a block that has moved because of changes elsewhere in the
+// file is still matched. If several blocks in a file have the same source code, they are matched
+// in order. If a file cannot be read, its blocks are matched by position instead.
+func DiffCoverage(oldProfile, newProfile *CoverageProfile, opts EnforcerOptions) (CoverageDiff, error) {
+	var diff CoverageDiff
+
+	oldOpts := opts
+	oldOpts.SourceDir = opts.OldSourceDir
+	oldResult, err := AnalyzeCoverage(oldProfile, oldOpts)
+	if err != nil {
+		return diff, fmt.Errorf("in old profile: %s", err)
+	}
+	newResult, err := AnalyzeCoverage(newProfile, opts)
+	if err != nil {
+		return diff, fmt.Errorf("in new profile: %s", err)
+	}
+
+	oldReport, newReport := NewSummaryReport(oldResult, oldOpts), NewSummaryReport(newResult, opts)
+	diff.OldCoverage, diff.NewCoverage = oldReport.GetTotalCoverage(), newReport.GetTotalCoverage()
+	diff.Packages = diffPackages(oldReport.Packages, newReport.Packages)
+
+	oldBlocksByFingerprint := make(map[string][]CodeBlockCoverage)
+	for _, fb := range getFingerprintedBlocks(oldResult, oldOpts) {
+		oldBlocksByFingerprint[fb.fingerprint] = append(oldBlocksByFingerprint[fb.fingerprint], fb.block)
+	}
+	minHits := getMinHits(opts)
+	for _, fb := range getFingerprintedBlocks(newResult, opts) {
+		candidates := oldBlocksByFingerprint[fb.fingerprint]
+		if len(candidates) == 0 {
+			continue
+		}
+		oldBlock := candidates[0]
+		oldBlocksByFingerprint[fb.fingerprint] = candidates[1:]
+		db := CoverageDiffBlock{OldRange: oldBlock.CodeRange, NewRange: fb.block.CodeRange, StatementCount: fb.block.StatementCount}
+		wasCovered, isNowCovered := isCovered(oldBlock.CoverageCount, minHits), isCovered(fb.block.CoverageCount, minHits)
+		switch {
+		case wasCovered && !isNowCovered:
+			diff.NewlyUncoveredBlocks = append(diff.NewlyUncoveredBlocks, db)
+		case !wasCovered && isNowCovered:
+			diff.NewlyCoveredBlocks = append(diff.NewlyCoveredBlocks, db)
+		}
+	}
+
+	return diff, nil
+}
+
+// Output writes the comparison in text format.
+func (d CoverageDiff) Output(writer io.Writer, opts EnforcerOptions) {
+	fmt.Fprintf(writer, "Coverage changes (%s):\n", getEffectiveMetric(opts))
+	tw := tabwriter.NewWriter(writer, 1, 4, 1, ' ', 0)
+	writeDiffRow(tw, "total", &d.OldCoverage, &d.NewCoverage, opts)
+	for _, p := range d.Packages {
+		writeDiffRow(tw, "  "+p.Path, p.Old, p.New, opts)
+		for _, f := range p.Files {
+			if f.Old == nil || f.New == nil || *f.Old != *f.New {
+				writeDiffRow(tw, "    "+f.FileName, f.Old, f.New, opts)
+			}
+		}
+	}
+	tw.Flush()
+	fmt.Fprintln(writer)
+
+	if len(d.NewlyUncoveredBlocks) != 0 {
+		fmt.Fprintln(writer, "Newly uncovered blocks:")
+		writeDiffBlocks(writer, d.NewlyUncoveredBlocks)
+		fmt.Fprintln(writer)
+	}
+	if len(d.NewlyCoveredBlocks) != 0 {
+		fmt.Fprintln(writer, "Newly covered blocks:")
+		writeDiffBlocks(writer, d.NewlyCoveredBlocks)
+		fmt.Fprintln(writer)
+	}
+	fmt.Fprintf(writer, "%d newly uncovered block(s), %d newly covered block(s)\n",
+		len(d.NewlyUncoveredBlocks), len(d.NewlyCoveredBlocks))
+}
+
+func writeDiffRow(tw io.Writer, desc string, oldCoverage, newCoverage *SummaryReportCoverage, opts EnforcerOptions) {
+	describe := func(c *SummaryReportCoverage) string {
+		if c == nil {
+			return "-\t"
+		}
+		covered, total := c.GetMetric(opts.Metric)
+		return fmt.Sprintf("%d/%d\t%s", covered, total, formatPercentCell(*c, opts))
+	}
+	var change string
+	switch {
+	case oldCoverage == nil:
+		change = "new"
+	case newCoverage == nil:
+		change = "removed"
+	default:
+		change = fmt.Sprintf("%+d%%", newCoverage.GetMetricPercent(opts.Metric)-oldCoverage.GetMetricPercent(opts.Metric))
+	}
+	fmt.Fprintf(tw, "%s\t%s\t->\t%s\t%s\t\n", desc, describe(oldCoverage), describe(newCoverage), change)
+}
+
+func writeDiffBlocks(writer io.Writer, blocks []CoverageDiffBlock) {
+	for _, b := range blocks {
+		fmt.Fprintf(writer, "%s %d-%d (%s)", b.NewRange.FilePath, b.NewRange.StartLine, b.NewRange.EndLine,
+			describeStatementCount(b.StatementCount))
+		if b.OldRange.StartLine != b.NewRange.StartLine || b.OldRange.EndLine != b.NewRange.EndLine {
+			fmt.Fprintf(writer, " (was %d-%d)", b.OldRange.StartLine, b.OldRange.EndLine)
+		}
+		fmt.Fprintln(writer)
+	}
+}
+
+func diffPackages(oldPackages, newPackages []SummaryReportPackage) []CoverageDiffPackage {
+	oldByPath := make(map[string]SummaryReportPackage)
+	newByPath := make(map[string]SummaryReportPackage)
+	var paths []string
+	for _, p := range oldPackages {
+		oldByPath[p.FullPackagePath] = p
+		paths = append(paths, p.FullPackagePath)
+	}
+	for _, p := range newPackages {
+		if _, ok := oldByPath[p.FullPackagePath]; !ok {
+			paths = append(paths, p.FullPackagePath)
+		}
+		newByPath[p.FullPackagePath] = p
+	}
+	sort.Strings(paths)
+
+	ret := make([]CoverageDiffPackage, 0, len(paths))
+	for _, packagePath := range paths {
+		op, inOld := oldByPath[packagePath]
+		np, inNew := newByPath[packagePath]
+		dp := CoverageDiffPackage{Path: packagePath, Files: diffFiles(op.Files, np.Files)}
+		if inOld {
+			dp.Old = &op.Coverage
+		}
+		if inNew {
+			dp.New = &np.Coverage
+		}
+		ret = append(ret, dp)
+	}
+	return ret
+}
+
+func diffFiles(oldFiles, newFiles []SummaryReportFile) []CoverageDiffFile {
+	oldByName := make(map[string]SummaryReportFile)
+	newByName := make(map[string]SummaryReportFile)
+	var names []string
+	for _, f := range oldFiles {
+		oldByName[f.FileName] = f
+		names = append(names, f.FileName)
+	}
+	for _, f := range newFiles {
+		if _, ok := oldByName[f.FileName]; !ok {
+			names = append(names, f.FileName)
+		}
+		newByName[f.FileName] = f
+	}
+	sort.Strings(names)
+
+	ret := make([]CoverageDiffFile, 0, len(names))
+	for _, name := range names {
+		df := CoverageDiffFile{FileName: name}
+		if of, ok := oldByName[name]; ok {
+			df.Old = &of.Coverage
+		}
+		if nf, ok := newByName[name]; ok {
+			df.New = &nf.Coverage
+		}
+		ret = append(ret, df)
+	}
+	return ret
+}
+
+type fingerprintedBlock struct {
+	fingerprint string
+	block       CodeBlockCoverage
+}
+
+// getFingerprintedBlocks returns all of the blocks in the analyzer result, each with a string that
+// identifies it by its file path and its source code.
+func getFingerprintedBlocks(result AnalyzerResult, opts EnforcerOptions) []fingerprintedBlock {
+	var ret []fingerprintedBlock
+	for _, p := range result.Packages {
+		for _, f := range p.Files {
+			filePath := path.Join(p.RelativePath, f.FileName)
+			var lines []string
+			if source, err := ioutil.ReadFile(filepath.Join(opts.SourceDir, filePath)); err == nil {
+				lines = strings.Split(string(source), "\n")
+			}
+			for _, b := range f.Blocks {
+				ret = append(ret, fingerprintedBlock{fingerprint: getBlockFingerprint(filePath, lines, b.CodeRange), block: b})
+			}
+		}
+	}
+	return ret
+}
+
+// getBlockFingerprint returns a string that identifies a block by its file path and its source
+// code, with all whitespace sequences replaced by a single space. If the source code is unavailable,
+// or the block's range is not within the file, the block is identified by its position instead.
+func getBlockFingerprint(filePath string, lines []string, r CodeRange) string {
+	if r.StartLine < 1 || r.EndLine < r.StartLine || r.EndLine > len(lines) ||
+		r.StartColumn > len(lines[r.StartLine-1])+1 || r.EndColumn > len(lines[r.EndLine-1])+1 {
+		return fmt.Sprintf("%s@%d.%d,%d.%d", filePath, r.StartLine, r.StartColumn, r.EndLine, r.EndColumn)
+	}
+	var parts []string
+	for lineNum := r.StartLine; lineNum <= r.EndLine; lineNum++ {
+		line := lines[lineNum-1]
+		start, end := r.getColumnSpan(lineNum, line)
+		_, within, _ := splitLineAtColumns(line, start, end)
+		parts = append(parts, within)
+	}
+	return filePath + ":" + strings.Join(strings.Fields(strings.Join(parts, " ")), " ")
+}
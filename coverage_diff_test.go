@@ -0,0 +1,219 @@
+package main
+
+import (
+	"bytes"
+	"regexp"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	testDataDiffOldFile  = "coverage_data_for_diff_old"
+	testDataDiffNewFile  = "coverage_data_for_diff_new"
+	testDataDiffOldDir   = "oldsource"
+	testDataDiffFilePath = testDataPackagePath + "/gosource/sample.go"
+)
+
+func withDiffTestProfiles(oldFile, newFile string, action func(oldProfile, newProfile *CoverageProfile)) {
+	inTestDataDir(func() {
+		oldProfile, err := readCoverageProfileFile(oldFile)
+		if err != nil {
+			panic(err)
+		}
+		newProfile, err := readCoverageProfileFile(newFile)
+		if err != nil {
+			panic(err)
+		}
+		action(oldProfile, newProfile)
+	})
+}
+
+func TestDiffCoverage(t *testing.T) {
+	t.Run("matches blocks that have moved", func(t *testing.T) {
+		withDiffTestProfiles(testDataDiffOldFile, testDataDiffNewFile, func(oldProfile, newProfile *CoverageProfile) {
+			opts := testBaseOptions
+			opts.OldSourceDir = testDataDiffOldDir
+			diff, err := DiffCoverage(oldProfile, newProfile, opts)
+			require.NoError(t, err)
+
+			assert.Equal(t, []CoverageDiffBlock{
+				{
+					OldRange:       CodeRange{testDataDiffFilePath, 21, 18, 23, 3},
+					NewRange:       CodeRange{testDataDiffFilePath, 17, 18, 19, 3},
+					StatementCount: 1,
+				},
+			}, diff.NewlyUncoveredBlocks)
+			assert.Equal(t, []CoverageDiffBlock{
+				{
+					OldRange:       CodeRange{testDataDiffFilePath, 14, 12, 16, 3},
+					NewRange:       CodeRange{testDataDiffFilePath, 10, 12, 12, 3},
+					StatementCount: 1,
+				},
+			}, diff.NewlyCoveredBlocks)
+		})
+	})
+
+	t.Run("blocks do not match if the old source is not available", func(t *testing.T) {
+		withDiffTestProfiles(testDataDiffOldFile, testDataDiffNewFile, func(oldProfile, newProfile *CoverageProfile) {
+			diff, err := DiffCoverage(oldProfile, newProfile, testBaseOptions)
+			require.NoError(t, err)
+			assert.Nil(t, diff.NewlyUncoveredBlocks)
+			assert.Nil(t, diff.NewlyCoveredBlocks)
+		})
+	})
+
+	t.Run("applies filtering to both profiles", func(t *testing.T) {
+		withDiffTestProfiles(testDataDiffOldFile, testDataDiffNewFile, func(oldProfile, newProfile *CoverageProfile) {
+			opts := testBaseOptions
+			opts.OldSourceDir = testDataDiffOldDir
+			opts.SkipCodePattern = regexp.MustCompile("panic")
+			diff, err := DiffCoverage(oldProfile, newProfile, opts)
+			require.NoError(t, err)
+
+			assert.Len(t, diff.NewlyUncoveredBlocks, 1)
+			assert.Nil(t, diff.NewlyCoveredBlocks)
+			assert.Equal(t, 7, diff.OldCoverage.TotalStatements)
+			assert.Equal(t, 7, diff.NewCoverage.TotalStatements)
+		})
+	})
+
+	t.Run("compares packages and files", func(t *testing.T) {
+		withDiffTestProfiles(testDataReportNotPassFile, testDataReportPassFile, func(oldProfile, newProfile *CoverageProfile) {
+			diff, err := DiffCoverage(oldProfile, newProfile, testBaseOptions)
+			require.NoError(t, err)
+
+			assert.Equal(t, 15, diff.OldCoverage.TotalStatements)
+			assert.Equal(t, 6, diff.NewCoverage.TotalStatements)
+			require.Len(t, diff.Packages, 2)
+
+			p1 := diff.Packages[0]
+			assert.Equal(t, testDataPackagePath, p1.Path)
+			require.NotNil(t, p1.Old)
+			require.NotNil(t, p1.New)
+			assert.Equal(t, 8, p1.Old.TotalStatements)
+			assert.Equal(t, 6, p1.New.TotalStatements)
+			var fileNames []string
+			for _, f := range p1.Files {
+				fileNames = append(fileNames, f.FileName)
+			}
+			assert.Equal(t, []string{"first", "second", "third"}, fileNames)
+			assert.NotNil(t, p1.Files[2].Old)
+			assert.Nil(t, p1.Files[2].New)
+
+			p2 := diff.Packages[1]
+			assert.Equal(t, testDataPackagePath+"/otherpackage", p2.Path)
+			assert.NotNil(t, p2.Old)
+			assert.Nil(t, p2.New)
+		})
+	})
+
+	t.Run("reports errors from either profile", func(t *testing.T) {
+		withDiffTestProfiles(testDataDiffOldFile, testDataDiffNewFile, func(oldProfile, newProfile *CoverageProfile) {
+			opts := EnforcerOptions{PackagePath: "wrong-package"}
+			_, err := DiffCoverage(oldProfile, newProfile, opts)
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), "in old profile")
+		})
+		withDiffTestProfiles(testDataCountsFile, testDataDiffNewFile, func(oldProfile, newProfile *CoverageProfile) {
+			opts := testBaseOptions
+			opts.MinHits = 2
+			_, err := DiffCoverage(oldProfile, newProfile, opts)
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), "in new profile")
+		})
+	})
+}
+
+func TestCoverageDiffOutput(t *testing.T) {
+	withDiffTestProfiles(testDataDiffOldFile, testDataDiffNewFile, func(oldProfile, newProfile *CoverageProfile) {
+		opts := testBaseOptions
+		opts.OldSourceDir = testDataDiffOldDir
+		diff, err := DiffCoverage(oldProfile, newProfile, opts)
+		require.NoError(t, err)
+
+		buf := new(bytes.Buffer)
+		diff.Output(buf, opts)
+		s := regexp.MustCompile(" +").ReplaceAllString(buf.String(), " ")
+		s = regexp.MustCompile(" \n").ReplaceAllString(s, "\n")
+		assert.Equal(t, `Coverage changes (statements):
+total 7/8 (87%) -> 6/7 (85%) -2%
+ base-package/gosource 7/8 (87%) -> 6/7 (85%) -2%
+ sample.go 7/8 (87%) -> 6/7 (85%) -2%
+
+Newly uncovered blocks:
+base-package/gosource/sample.go 17-19 (1 statement) (was 21-23)
+
+Newly covered blocks:
+base-package/gosource/sample.go 10-12 (1 statement) (was 14-16)
+
+1 newly uncovered block(s), 1 newly covered block(s)
+`, s)
+	})
+}
+
+func TestCoverageDiffOutputWithAddedAndRemovedPackages(t *testing.T) {
+	t.Run("removed", func(t *testing.T) {
+		assert.Equal(t, `Coverage changes (statements):
+total 6/15 (40%) -> 6/6 (100%) +60%
+ base-package 6/8 (75%) -> 6/6 (100%) +25%
+ first 2/4 (50%) -> 2/2 (100%) +50%
+ third 0/0 (100%) -> - removed
+ base-package/otherpackage 0/7 (0%) -> - removed
+ first 0/7 (0%) -> - removed
+
+0 newly uncovered block(s), 0 newly covered block(s)
+`, getDiffOutputForTest(t, testDataReportNotPassFile, testDataReportPassFile))
+	})
+
+	t.Run("new", func(t *testing.T) {
+		assert.Equal(t, `Coverage changes (statements):
+total 6/6 (100%) -> 6/15 (40%) -60%
+ base-package 6/6 (100%) -> 6/8 (75%) -25%
+ first 2/2 (100%) -> 2/4 (50%) -50%
+ third - -> 0/0 (100%) new
+ base-package/otherpackage - -> 0/7 (0%) new
+ first - -> 0/7 (0%) new
+
+0 newly uncovered block(s), 0 newly covered block(s)
+`, getDiffOutputForTest(t, testDataReportPassFile, testDataReportNotPassFile))
+	})
+}
+
+func TestCoverageDiffOutputWithNoChanges(t *testing.T) {
+	assert.Equal(t, `Coverage changes (statements):
+total 6/6 (100%) -> 6/6 (100%) +0%
+ base-package 6/6 (100%) -> 6/6 (100%) +0%
+
+0 newly uncovered block(s), 0 newly covered block(s)
+`, getDiffOutputForTest(t, testDataReportPassFile, testDataReportPassFile))
+}
+
+func getDiffOutputForTest(t *testing.T, oldFile, newFile string) string {
+	var s string
+	withDiffTestProfiles(oldFile, newFile, func(oldProfile, newProfile *CoverageProfile) {
+		diff, err := DiffCoverage(oldProfile, newProfile, testBaseOptions)
+		require.NoError(t, err)
+
+		buf := new(bytes.Buffer)
+		diff.Output(buf, testBaseOptions)
+		s = regexp.MustCompile(" +").ReplaceAllString(buf.String(), " ")
+		s = regexp.MustCompile(" \n").ReplaceAllString(s, "\n")
+	})
+	return s
+}
+
+func TestGetBlockFingerprint(t *testing.T) {
+	lines := []string{"func f() {", "\tif x {", "\t\treturn  y", "\t}", "}"}
+	otherLines := []string{"// comment", "func f() {", "  if x {", "    return y", "  }", "}"}
+
+	fp := getBlockFingerprint("a.go", lines, CodeRange{"p/a.go", 2, 7, 4, 3})
+	assert.Equal(t, "a.go:{ return y }", fp)
+	assert.Equal(t, fp, getBlockFingerprint("a.go", otherLines, CodeRange{"p/a.go", 3, 8, 5, 4}))
+	assert.NotEqual(t, fp, getBlockFingerprint("b.go", otherLines, CodeRange{"p/b.go", 3, 8, 5, 4}))
+
+	assert.Equal(t, "a.go@5.1,6.1", getBlockFingerprint("a.go", lines, CodeRange{"p/a.go", 5, 1, 6, 1}))
+	assert.Equal(t, "a.go@2.20,2.30", getBlockFingerprint("a.go", lines, CodeRange{"p/a.go", 2, 20, 2, 30}))
+	assert.Equal(t, "a.go@1.1,2.1", getBlockFingerprint("a.go", nil, CodeRange{"p/a.go", 1, 1, 2, 1}))
+}
@@ -62,6 +62,13 @@ func (r CodeRange) GetPackagePathAndFileName() (string, string) {
 	return p, strings.TrimPrefix(r.FilePath, p+"/")
 }
 
+// GetRelativeFilePath returns the file's path relative to the directory of the base package. For
+// instance, if the base package is "github.com/a/b", the relative path of "github.com/a/b/c/d.go" is
+// "c/d.go".
+func (r CodeRange) GetRelativeFilePath(basePackagePath string) string {
+	return strings.TrimPrefix(r.FilePath, basePackagePath+"/")
+}
+
 // ReadCoverageProfile attempts to parse a coverage profile generated by "go test".
 //
 // The standard format of this file is a first line "mode: X" where X is one of the coverage modes
@@ -106,6 +113,12 @@ func ReadCoverageProfile(reader io.Reader) (*CoverageProfile, error) {
 	return ret, nil
 }
 
+// HasExecutionCounts returns true if the coverage mode is "count" or "atomic", meaning that the
+// coverage counts are the number of times that each block was executed rather than just 0 or 1.
+func (cp CoverageProfile) HasExecutionCounts() bool {
+	return cp.CoverageMode == "count" || cp.CoverageMode == "atomic"
+}
+
 // WriteTo writes the profile data to a Writer in the same format that it was parsed from.
 func (cp CoverageProfile) WriteTo(writer io.Writer) error {
 	bw := bufio.NewWriter(writer)
@@ -140,11 +153,16 @@ func (cp CoverageProfile) WriteTo(writer io.Writer) error {
 // through the covered code paths. If any of those lines has a nonzero coverage count, then that code
 // range was covered. This function reduces any number of items that reference the same code range to
 // a single item that has a nonzero coverage count if any such count was present, or zero otherwise.
+// If the mode is "count" or "atomic", the coverage count of that item is the sum of all of the
+// counts, since each of them is a number of times that the code was executed by some test binary.
 // It also sorts items in ascending order of package path, file path, and starting line number.
 func (cp CoverageProfile) GetUniqueBlocks() []CodeBlockCoverage {
 	workMap := make(map[CodeRange]CodeBlockCoverage)
 	for _, b := range cp.Blocks {
-		if b.CoverageCount > 0 {
+		if existing, ok := workMap[b.CodeRange]; ok && cp.HasExecutionCounts() {
+			existing.CoverageCount += b.CoverageCount
+			workMap[b.CodeRange] = existing
+		} else if b.CoverageCount > 0 {
 			workMap[b.CodeRange] = b
 		} else {
 			if _, ok := workMap[b.CodeRange]; !ok {
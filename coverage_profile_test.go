@@ -51,6 +51,21 @@ func TestCoverageProfileGetUniqueBlocks(t *testing.T) {
 	})
 }
 
+func TestCoverageProfileGetUniqueBlocksWithCounts(t *testing.T) {
+	withValidTestProfile(testDataCountsFile, func(cp *CoverageProfile) {
+		assert.True(t, cp.HasExecutionCounts())
+		blocks := cp.GetUniqueBlocks()
+		require.Len(t, blocks, 7)
+		assert.Equal(t, 150, blocks[0].CoverageCount, "counts of duplicate blocks are added together")
+		assert.Equal(t, 5, blocks[1].CoverageCount)
+	})
+}
+
+func TestCodeRangeGetRelativeFilePath(t *testing.T) {
+	assert.Equal(t, "d.go", CodeRange{FilePath: "github.com/a/b/d.go"}.GetRelativeFilePath("github.com/a/b"))
+	assert.Equal(t, "c/d.go", CodeRange{FilePath: "github.com/a/b/c/d.go"}.GetRelativeFilePath("github.com/a/b"))
+}
+
 func TestCoverageProfileWriteTo(t *testing.T) {
 	t.Run("output matches input", func(t *testing.T) {
 		withValidTestProfile(testDataMainFile, func(cp *CoverageProfile) {
@@ -0,0 +1,109 @@
+package main
+
+import (
+	"go/ast"
+	"go/parser"
+	"go/token"
+)
+
+// sourceFunction is the location of a top-level function or method declaration in a Go source file.
+type sourceFunction struct {
+	name     string
+	startPos token.Position
+	endPos   token.Position
+}
+
+// parseSourceFunctions returns the top-level functions and methods declared in a Go source file, in
+// the order they appear. It returns nil if the file cannot be read or is not valid Go source code.
+func parseSourceFunctions(filePath string) []sourceFunction {
+	fset := token.NewFileSet()
+	file, err := parser.ParseFile(fset, filePath, nil, 0)
+	if err != nil {
+		return nil
+	}
+	var ret []sourceFunction
+	for _, decl := range file.Decls {
+		fd, ok := decl.(*ast.FuncDecl)
+		if !ok || fd.Body == nil {
+			continue
+		}
+		name := fd.Name.Name
+		if fd.Recv != nil && len(fd.Recv.List) > 0 {
+			if recvName := getReceiverTypeName(fd.Recv.List[0].Type); recvName != "" {
+				name = recvName + "." + name
+			}
+		}
+		ret = append(ret, sourceFunction{
+			name:     name,
+			startPos: fset.Position(fd.Pos()),
+			endPos:   fset.Position(fd.End()),
+		})
+	}
+	return ret
+}
+
+func getReceiverTypeName(expr ast.Expr) string {
+	switch e := expr.(type) {
+	case *ast.StarExpr:
+		return getReceiverTypeName(e.X)
+	case *ast.IndexExpr: // generic type with one type parameter
+		return getReceiverTypeName(e.X)
+	case *ast.Ident:
+		return e.Name
+	}
+	return getGenericReceiverTypeName(expr)
+}
+
+// contains returns true if the start of the code range is within the function declaration.
+func (f sourceFunction) contains(r CodeRange) bool {
+	afterStart := r.StartLine > f.startPos.Line ||
+		(r.StartLine == f.startPos.Line && r.StartColumn >= f.startPos.Column)
+	beforeEnd := r.StartLine < f.endPos.Line ||
+		(r.StartLine == f.endPos.Line && r.StartColumn < f.endPos.Column)
+	return afterStart && beforeEnd
+}
+
+// addFunctionInfo determines which function each block in the file belongs to, and fills in the
+// Functions field of the file result and the FunctionName field of each uncovered block. If the file
+// cannot be parsed as Go source code, it does nothing.
+func addFunctionInfo(f *AnalyzerFileResult, filePath string, minHits int) {
+	funcs := parseSourceFunctions(filePath)
+	if len(funcs) == 0 {
+		return
+	}
+	findFunction := func(r CodeRange) int {
+		for i, fn := range funcs {
+			if fn.contains(r) {
+				return i
+			}
+		}
+		return -1
+	}
+
+	results := make([]*AnalyzerFunctionResult, len(funcs))
+	for _, b := range f.Blocks {
+		i := findFunction(b.CodeRange)
+		if i < 0 {
+			continue
+		}
+		if results[i] == nil {
+			results[i] = &AnalyzerFunctionResult{Name: funcs[i].name, StartLine: funcs[i].startPos.Line}
+		}
+		results[i].Blocks = append(results[i].Blocks, b)
+		results[i].TotalStatements += b.StatementCount
+		if isCovered(b.CoverageCount, minHits) {
+			results[i].CoveredStatements += b.StatementCount
+		}
+	}
+	for _, r := range results {
+		if r != nil {
+			f.Functions = append(f.Functions, *r)
+		}
+	}
+
+	for i, b := range f.UncoveredBlocks {
+		if fi := findFunction(b.CodeRange); fi >= 0 {
+			f.UncoveredBlocks[i].FunctionName = funcs[fi].name
+		}
+	}
+}
@@ -0,0 +1,15 @@
+//go:build go1.18
+// +build go1.18
+
+package main
+
+import "go/ast"
+
+// getGenericReceiverTypeName returns the name of a receiver type that has several type parameters,
+// such as G[K, V]. The syntax tree node for this was added in Go 1.18.
+func getGenericReceiverTypeName(expr ast.Expr) string {
+	if e, ok := expr.(*ast.IndexListExpr); ok {
+		return getReceiverTypeName(e.X)
+	}
+	return ""
+}
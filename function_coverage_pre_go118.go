@@ -0,0 +1,12 @@
+//go:build !go1.18
+// +build !go1.18
+
+package main
+
+import "go/ast"
+
+// getGenericReceiverTypeName always returns "", because before Go 1.18 the parser cannot produce a
+// receiver type with several type parameters.
+func getGenericReceiverTypeName(expr ast.Expr) string {
+	return ""
+}
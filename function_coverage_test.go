@@ -0,0 +1,114 @@
+package main
+
+import (
+	"bytes"
+	"go/parser"
+	"regexp"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testDataFuncsFile = "coverage_data_for_funcs"
+
+func TestAnalyzeCoverageFunctions(t *testing.T) {
+	t.Run("maps blocks to functions", func(t *testing.T) {
+		withValidTestProfile(testDataFuncsFile, func(cp *CoverageProfile) {
+			result, err := AnalyzeCoverage(cp, testBaseOptions)
+			require.NoError(t, err)
+
+			require.Len(t, result.Packages, 1)
+			require.Len(t, result.Packages[0].Files, 1)
+			f := result.Packages[0].Files[0]
+			require.Len(t, f.Blocks, 7)
+			assert.Equal(t, []AnalyzerFunctionResult{
+				{Name: "Add", StartLine: 3, TotalStatements: 1, CoveredStatements: 1, Blocks: f.Blocks[0:1]},
+				{Name: "T.Inc", StartLine: 9, TotalStatements: 3, CoveredStatements: 2, Blocks: f.Blocks[1:4]},
+				{Name: "T.Get", StartLine: 16, TotalStatements: 3, CoveredStatements: 0, Blocks: f.Blocks[4:7]},
+			}, f.Functions)
+
+			var names []string
+			for _, b := range f.UncoveredBlocks {
+				names = append(names, b.FunctionName)
+			}
+			assert.Equal(t, []string{"T.Inc", "T.Get", "T.Get", "T.Get"}, names)
+		})
+	})
+
+	t.Run("respects -skipcode", func(t *testing.T) {
+		withValidTestProfile(testDataFuncsFile, func(cp *CoverageProfile) {
+			opts := testBaseOptions
+			opts.SkipCodePattern = regexp.MustCompile("panic")
+			result, err := AnalyzeCoverage(cp, opts)
+			require.NoError(t, err)
+
+			f := result.Packages[0].Files[0]
+			assert.Equal(t, AnalyzerFunctionResult{Name: "T.Inc", StartLine: 9, TotalStatements: 2, CoveredStatements: 2,
+				Blocks: f.Blocks[1:3]}, f.Functions[1])
+		})
+	})
+
+	t.Run("no function information if the source post-pass is skipped", func(t *testing.T) {
+		withValidTestProfile(testDataFuncsFile, func(cp *CoverageProfile) {
+			opts := testBaseOptions
+			opts.SkipSourcePostPass = true
+			result, err := AnalyzeCoverage(cp, opts)
+			require.NoError(t, err)
+
+			f := result.Packages[0].Files[0]
+			assert.Nil(t, f.Functions)
+			assert.Equal(t, "", f.UncoveredBlocks[0].FunctionName)
+		})
+	})
+
+	t.Run("no function information for non-Go files", func(t *testing.T) {
+		withValidTestProfile(testDataMainFile, func(cp *CoverageProfile) {
+			result, err := AnalyzeCoverage(cp, testBaseOptions)
+			require.NoError(t, err)
+			assert.Nil(t, result.Packages[0].Files[0].Functions)
+		})
+	})
+}
+
+func TestReportOutputWithFunctionStats(t *testing.T) {
+	withValidTestProfile(testDataFuncsFile, func(cp *CoverageProfile) {
+		opts := testBaseOptions
+		opts.ShowFunctionStats = true
+		result, err := AnalyzeCoverage(cp, opts)
+		require.NoError(t, err)
+		report := NewSummaryReport(result, opts)
+
+		buf := new(bytes.Buffer)
+		report.Output(buf, opts)
+		s := regexp.MustCompile(" +\n").ReplaceAllString(buf.String(), "\n")
+		assert.Equal(t, `base-package/gosource/sample.go:3:  Add          1/1 (100%)
+base-package/gosource/sample.go:9:  T.Inc        2/3 (66%)
+base-package/gosource/sample.go:16: T.Get        0/3 (0%)
+total:                              (statements) 3/7 (42%)
+
+Uncovered blocks detected:
+base-package/gosource/sample.go 10-12 (in T.Inc)
+base-package/gosource/sample.go 16-17 (in T.Get)
+base-package/gosource/sample.go 17-19 (in T.Get)
+base-package/gosource/sample.go 20-21 (in T.Get)
+`, s)
+	})
+}
+
+func TestGetReceiverTypeName(t *testing.T) {
+	for expr, expected := range map[string]string{
+		"T":        "T",
+		"*T":       "T",
+		"G[K]":     "G",
+		"*G[K]":    "G",
+		"G[K, V]":  "G",
+		"*G[K, V]": "G",
+		"other.T":  "",
+		"func() T": "",
+	} {
+		e, err := parser.ParseExpr(expr)
+		require.NoError(t, err)
+		assert.Equal(t, expected, getReceiverTypeName(e), expr)
+	}
+}
@@ -0,0 +1,46 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// WriteGitHubReport writes the report data as GitHub Actions workflow commands. Each uncovered block
+// becomes an "::error" command with the block's location, so that GitHub shows it as an annotation on
+// the pull request diff. Each rule that did not pass becomes an "::error" command without a location.
+//
+// File paths are made relative to the base package directory, so for the annotations to appear in
+// the right place, the base package directory must be the root of the repository.
+func WriteGitHubReport(writer io.Writer, report SummaryReport, result AnalyzerResult, opts EnforcerOptions) error {
+	bw := bufio.NewWriter(writer)
+	for _, b := range report.UncoveredBlocks {
+		r := b.CodeRange
+		fmt.Fprintf(bw, "::error file=%s,line=%d,endLine=%d,col=%d,endColumn=%d,title=%s::%s\n",
+			escapeGitHubProperty(r.GetRelativeFilePath(opts.PackagePath)),
+			r.StartLine,
+			r.EndLine,
+			r.StartColumn,
+			r.EndColumn,
+			escapeGitHubProperty("Uncovered block"),
+			escapeGitHubData(fmt.Sprintf("Lines %d-%d are not covered by tests", r.StartLine, r.EndLine)),
+		)
+	}
+	for _, rule := range report.Rules {
+		if !rule.Pass {
+			fmt.Fprintf(bw, "::error::%s\n", escapeGitHubData(rule.Name+": "+rule.Message))
+		}
+	}
+	return bw.Flush()
+}
+
+// escapeGitHubData escapes the message part of a workflow command.
+func escapeGitHubData(s string) string {
+	return strings.NewReplacer("%", "%25", "\r", "%0D", "\n", "%0A").Replace(s)
+}
+
+// escapeGitHubProperty escapes a property value in a workflow command.
+func escapeGitHubProperty(s string) string {
+	return strings.NewReplacer("%", "%25", "\r", "%0D", "\n", "%0A", ":", "%3A", ",", "%2C").Replace(s)
+}
@@ -0,0 +1,29 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGitHubReport(t *testing.T) {
+	t.Run("report from data with coverage gaps", func(t *testing.T) {
+		withValidTestProfile(testDataReportNotPassFile, func(cp *CoverageProfile) {
+			assert.Equal(t, `::error file=first,line=3,endLine=4,col=1,endColumn=1,title=Uncovered block::Lines 3-4 are not covered by tests
+::error file=otherpackage/first,line=1,endLine=3,col=1,endColumn=1,title=Uncovered block::Lines 1-3 are not covered by tests
+::error::uncovered-blocks: 2 uncovered block(s) detected
+`, writeReportForTest(t, cp, testBaseOptions, "github"))
+		})
+	})
+
+	t.Run("report from data with no coverage gaps", func(t *testing.T) {
+		withValidTestProfile(testDataReportPassFile, func(cp *CoverageProfile) {
+			assert.Equal(t, "", writeReportForTest(t, cp, testBaseOptions, "github"))
+		})
+	})
+}
+
+func TestEscapeGitHubCommandValues(t *testing.T) {
+	assert.Equal(t, "50%25 done%0Anext: a, b", escapeGitHubData("50% done\nnext: a, b"))
+	assert.Equal(t, "50%25 done%0Anext%3A a%2C b", escapeGitHubProperty("50% done\nnext: a, b"))
+}
@@ -0,0 +1,262 @@
+package main
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"sort"
+	"strings"
+	"text/tabwriter"
+	"time"
+)
+
+// sparklineChars are the characters used to draw a sparkline in the trend report, from lowest to
+// highest.
+const sparklineChars = "_.-=+*#"
+
+// HistoryRecord is one line in the JSON Lines file written by "-history", describing a single run.
+type HistoryRecord struct {
+	// Timestamp is the time of the run, in UTC.
+	Timestamp time.Time `json:"timestamp"`
+
+	// GitSHA is the commit hash of the Git checkout in the current directory. It is omitted if it
+	// could not be determined.
+	GitSHA string `json:"gitSHA,omitempty"`
+
+	// PackagePath is the base import path of the package that was analyzed.
+	PackagePath string `json:"packagePath"`
+
+	// Pass is true if the coverage scan passed.
+	Pass bool `json:"pass"`
+
+	// Coverage is the total coverage of all packages, after filtering.
+	Coverage JSONCoverage `json:"coverage"`
+
+	// Packages contains the coverage of each analyzed package, sorted by import path.
+	Packages []HistoryPackage `json:"packages"`
+}
+
+// HistoryPackage is package-level information in HistoryRecord.
+type HistoryPackage struct {
+	// Path is the full import path of the package.
+	Path string `json:"path"`
+
+	// Coverage is the total coverage of the files in this package.
+	Coverage JSONCoverage `json:"coverage"`
+}
+
+// NewHistoryRecord creates the record that is written to the "-history" file for a run.
+func NewHistoryRecord(report SummaryReport, opts EnforcerOptions, timestamp time.Time, gitSHA string) HistoryRecord {
+	rec := HistoryRecord{
+		Timestamp:   timestamp.UTC(),
+		GitSHA:      gitSHA,
+		PackagePath: opts.PackagePath,
+		Pass:        report.Pass,
+		Coverage:    makeJSONCoverage(report.GetTotalCoverage()),
+		Packages:    make([]HistoryPackage, 0, len(report.Packages)),
+	}
+	for _, p := range report.Packages {
+		rec.Packages = append(rec.Packages, HistoryPackage{Path: p.FullPackagePath, Coverage: makeJSONCoverage(p.Coverage)})
+	}
+	return rec
+}
+
+// AppendHistoryRecord adds a record to the end of the history file, creating the file if necessary.
+func AppendHistoryRecord(filePath string, rec HistoryRecord) error {
+	data, err := json.Marshal(rec)
+	if err != nil {
+		return err // COVERAGE: a HistoryRecord can always be marshaled
+	}
+	f, err := os.OpenFile(filePath, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		return err
+	}
+	if _, err = f.Write(append(data, '\n')); err != nil {
+		f.Close()
+		return err // COVERAGE: there is no way to simulate a write failure to a file in unit tests
+	}
+	return f.Close()
+}
+
+// ReadHistory parses the records in a history file, in the order they were written. Blank lines are
+// ignored.
+func ReadHistory(reader io.Reader) ([]HistoryRecord, error) {
+	var ret []HistoryRecord
+	scanner := bufio.NewScanner(reader)
+	scanner.Buffer(nil, 16*1024*1024) // records for large modules can exceed the default line limit
+	lineNum := 0
+	for scanner.Scan() {
+		lineNum++
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" {
+			continue
+		}
+		var rec HistoryRecord
+		if err := json.Unmarshal([]byte(line), &rec); err != nil {
+			return nil, fmt.Errorf("invalid history record at line %d (%s)", lineNum, err)
+		}
+		ret = append(ret, rec)
+	}
+	return ret, scanner.Err()
+}
+
+// WriteTrend writes the trend report for the "trend" command: the coverage of each package in the
+// latest run, the change since the previous run, and a sparkline of the last opts.TrendRuns runs.
+func WriteTrend(writer io.Writer, records []HistoryRecord, opts EnforcerOptions) {
+	if len(records) == 0 {
+		fmt.Fprintln(writer, "No runs have been recorded")
+		return
+	}
+	if opts.TrendRuns > 0 && len(records) > opts.TrendRuns {
+		records = records[len(records)-opts.TrendRuns:]
+	}
+	latest := records[len(records)-1]
+	var previous *HistoryRecord
+	if len(records) > 1 {
+		previous = &records[len(records)-2]
+	}
+
+	fmt.Fprintf(writer, "Latest run:   %s\n", describeHistoryRecord(latest))
+	if previous == nil {
+		fmt.Fprintln(writer, "Previous run: none")
+	} else {
+		fmt.Fprintf(writer, "Previous run: %s\n", describeHistoryRecord(*previous))
+	}
+	fmt.Fprintf(writer, "Showing %s coverage for the last %d run(s)\n\n", getEffectiveMetric(opts), len(records))
+
+	tw := tabwriter.NewWriter(writer, 1, 4, 1, ' ', 0)
+	writeTrendRow(tw, "total", records, opts, func(rec HistoryRecord) (JSONCoverage, bool) {
+		return rec.Coverage, true
+	})
+	for _, packagePath := range getHistoryPackagePaths(records) {
+		p := packagePath
+		writeTrendRow(tw, "  "+p, records, opts, func(rec HistoryRecord) (JSONCoverage, bool) {
+			for _, hp := range rec.Packages {
+				if hp.Path == p {
+					return hp.Coverage, true
+				}
+			}
+			return JSONCoverage{}, false
+		})
+	}
+	tw.Flush()
+}
+
+func writeTrendRow(
+	tw io.Writer,
+	desc string,
+	records []HistoryRecord,
+	opts EnforcerOptions,
+	getCoverage func(HistoryRecord) (JSONCoverage, bool),
+) {
+	percents := make([]int, len(records))
+	present := make([]bool, len(records))
+	for i, rec := range records {
+		if c, ok := getCoverage(rec); ok {
+			percents[i], present[i] = makeSummaryReportCoverage(c).GetMetricPercent(opts.Metric), true
+		}
+	}
+	last := len(records) - 1
+
+	var current, change string
+	if present[last] {
+		c, _ := getCoverage(records[last])
+		covered, total := makeSummaryReportCoverage(c).GetMetric(opts.Metric)
+		current = fmt.Sprintf("%d/%d\t(%d%%)", covered, total, percents[last])
+	} else {
+		current = "-\t"
+	}
+	switch {
+	case last == 0:
+	case !present[last]:
+		change = "removed"
+	case !present[last-1]:
+		change = "new"
+	default:
+		change = fmt.Sprintf("%+d%%", percents[last]-percents[last-1])
+	}
+	fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t\n", desc, current, change, makeSparkline(percents, present))
+}
+
+// makeSparkline draws a line of ASCII characters representing a series of percentages, scaled so
+// that the lowest value uses the lowest character and the highest value uses the highest character,
+// so that small changes are visible. If all the values are the same, they are scaled from 0 to 100
+// instead. Values that are not present are shown as spaces.
+func makeSparkline(percents []int, present []bool) string {
+	min, max := 100, 0
+	for i, p := range percents {
+		if present[i] {
+			if p < min {
+				min = p
+			}
+			if p > max {
+				max = p
+			}
+		}
+	}
+	if min >= max {
+		min, max = 0, 100
+	}
+	levels := len(sparklineChars) - 1
+	var sb strings.Builder
+	for i, p := range percents {
+		if !present[i] {
+			sb.WriteByte(' ')
+			continue
+		}
+		sb.WriteByte(sparklineChars[(p-min)*levels/(max-min)])
+	}
+	return sb.String()
+}
+
+// getHistoryPackagePaths returns the paths of all packages that appear in any of the records, sorted.
+func getHistoryPackagePaths(records []HistoryRecord) []string {
+	seen := make(map[string]bool)
+	var ret []string
+	for _, rec := range records {
+		for _, p := range rec.Packages {
+			if !seen[p.Path] {
+				seen[p.Path] = true
+				ret = append(ret, p.Path)
+			}
+		}
+	}
+	sort.Strings(ret)
+	return ret
+}
+
+func describeHistoryRecord(rec HistoryRecord) string {
+	s := rec.Timestamp.Format("2006-01-02 15:04:05 MST")
+	if rec.GitSHA != "" {
+		sha := rec.GitSHA
+		if len(sha) > 12 {
+			sha = sha[:12]
+		}
+		s += " (" + sha + ")"
+	}
+	return s
+}
+
+func makeSummaryReportCoverage(c JSONCoverage) SummaryReportCoverage {
+	return SummaryReportCoverage{
+		TotalStatements:   c.TotalStatements,
+		CoveredStatements: c.CoveredStatements,
+		TotalLines:        c.TotalLines,
+		CoveredLines:      c.CoveredLines,
+		TotalBlocks:       c.TotalBlocks,
+		CoveredBlocks:     c.CoveredBlocks,
+	}
+}
+
+// getGitCommitSHA returns the commit hash of the Git checkout in the current directory, or "" if
+// it could not be determined.
+func getGitCommitSHA() string {
+	out, err := exec.Command("git", "rev-parse", "HEAD").Output()
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSpace(string(out))
+}
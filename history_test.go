@@ -0,0 +1,168 @@
+package main
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func makeTestHistoryRecord(timestamp time.Time, total JSONCoverage, packages ...HistoryPackage) HistoryRecord {
+	return HistoryRecord{Timestamp: timestamp, PackagePath: "a", Coverage: total, Packages: packages}
+}
+
+func TestNewHistoryRecord(t *testing.T) {
+	report := SummaryReport{
+		Packages: []SummaryReportPackage{
+			{FullPackagePath: "a", Coverage: makeCoverage(10, 5, 8, 4, 4, 2)},
+			{FullPackagePath: "a/b", Coverage: makeCoverage(10, 10, 6, 6, 2, 2)},
+		},
+		Pass: true,
+	}
+	opts := EnforcerOptions{PackagePath: "a"}
+	timestamp := time.Date(2020, 1, 2, 3, 4, 5, 0, time.FixedZone("", 3600))
+
+	rec := NewHistoryRecord(report, opts, timestamp, "abc")
+	assert.Equal(t, HistoryRecord{
+		Timestamp:   timestamp.UTC(),
+		GitSHA:      "abc",
+		PackagePath: "a",
+		Pass:        true,
+		Coverage:    makeJSONCoverage(makeCoverage(20, 15, 14, 10, 6, 4)),
+		Packages: []HistoryPackage{
+			{Path: "a", Coverage: makeJSONCoverage(makeCoverage(10, 5, 8, 4, 4, 2))},
+			{Path: "a/b", Coverage: makeJSONCoverage(makeCoverage(10, 10, 6, 6, 2, 2))},
+		},
+	}, rec)
+}
+
+func TestAppendAndReadHistory(t *testing.T) {
+	dir, err := ioutil.TempDir("", "history")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+	filePath := filepath.Join(dir, "history.jsonl")
+
+	rec1 := makeTestHistoryRecord(time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC), makeJSONCoverage(makeCoverage(10, 5, 0, 0, 0, 0)))
+	rec2 := makeTestHistoryRecord(time.Date(2020, 1, 2, 0, 0, 0, 0, time.UTC), makeJSONCoverage(makeCoverage(10, 6, 0, 0, 0, 0)),
+		HistoryPackage{Path: "a", Coverage: makeJSONCoverage(makeCoverage(10, 6, 0, 0, 0, 0))})
+	require.NoError(t, AppendHistoryRecord(filePath, rec1))
+	require.NoError(t, AppendHistoryRecord(filePath, rec2))
+
+	data, err := ioutil.ReadFile(filePath)
+	require.NoError(t, err)
+	assert.Equal(t, 2, strings.Count(string(data), "\n"))
+
+	records, err := ReadHistory(bytes.NewReader(data))
+	require.NoError(t, err)
+	rec1.Packages = nil // an empty array is written as [] but read back as nil
+	records[0].Packages = nil
+	assert.Equal(t, []HistoryRecord{rec1, rec2}, records)
+}
+
+func TestAppendHistoryRecordError(t *testing.T) {
+	withTempDir(func(dir string) {
+		assert.Error(t, AppendHistoryRecord(dir, HistoryRecord{}))
+	})
+}
+
+func TestGetGitCommitSHA(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git is not installed")
+	}
+	withTempDir(func(dir string) {
+		inWorkingDir(dir, func() {
+			assert.Equal(t, "", getGitCommitSHA(), "not a Git checkout")
+
+			for _, args := range [][]string{
+				{"init", "-q"},
+				{"-c", "user.name=test", "-c", "user.email=test@example.com", "commit", "-q", "--allow-empty", "-m", "test"},
+			} {
+				require.NoError(t, exec.Command("git", args...).Run())
+			}
+			assert.Regexp(t, "^[0-9a-f]{40}$", getGitCommitSHA())
+		})
+	})
+}
+
+func TestReadHistory(t *testing.T) {
+	t.Run("ignores blank lines", func(t *testing.T) {
+		records, err := ReadHistory(strings.NewReader("\n{\"packagePath\":\"a\"}\n\n{\"packagePath\":\"b\"}\n"))
+		require.NoError(t, err)
+		require.Len(t, records, 2)
+		assert.Equal(t, "b", records[1].PackagePath)
+	})
+
+	t.Run("malformed record", func(t *testing.T) {
+		_, err := ReadHistory(strings.NewReader("{\"packagePath\":\"a\"}\nnot json\n"))
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "line 2")
+	})
+}
+
+func TestMakeSparkline(t *testing.T) {
+	assert.Equal(t, "_-#", makeSparkline([]int{50, 60, 80}, []bool{true, true, true}))
+	assert.Equal(t, "#_ #", makeSparkline([]int{90, 10, 0, 90}, []bool{true, true, false, true}))
+	assert.Equal(t, "+++", makeSparkline([]int{70, 70, 70}, []bool{true, true, true}))
+	assert.Equal(t, "##", makeSparkline([]int{100, 100}, []bool{true, true}))
+	assert.Equal(t, "  ", makeSparkline([]int{0, 0}, []bool{false, false}))
+}
+
+func TestWriteTrend(t *testing.T) {
+	day := func(d int) time.Time { return time.Date(2020, 1, d, 12, 0, 0, 0, time.UTC) }
+	cov := func(covered, total int) JSONCoverage {
+		return makeJSONCoverage(SummaryReportCoverage{TotalStatements: total, CoveredStatements: covered,
+			TotalLines: total, CoveredLines: total})
+	}
+	records := []HistoryRecord{
+		makeTestHistoryRecord(day(1), cov(10, 20), HistoryPackage{"a", cov(5, 10)}, HistoryPackage{"a/b", cov(5, 10)}),
+		makeTestHistoryRecord(day(2), cov(12, 20), HistoryPackage{"a", cov(7, 10)}, HistoryPackage{"a/b", cov(5, 10)}),
+		makeTestHistoryRecord(day(3), cov(18, 20), HistoryPackage{"a", cov(8, 10)}, HistoryPackage{"a/c", cov(10, 10)}),
+	}
+	records[2].GitSHA = "0123456789abcdef"
+
+	normalize := func(s string) string {
+		s = regexp.MustCompile(" +").ReplaceAllString(s, " ")
+		return regexp.MustCompile(" \n").ReplaceAllString(s, "\n")
+	}
+
+	t.Run("all runs", func(t *testing.T) {
+		buf := new(bytes.Buffer)
+		WriteTrend(buf, records, EnforcerOptions{TrendRuns: 10})
+		assert.Equal(t, `Latest run: 2020-01-03 12:00:00 UTC (0123456789ab)
+Previous run: 2020-01-02 12:00:00 UTC
+Showing statements coverage for the last 3 run(s)
+
+total 18/20 (90%) +30% _.#
+ a 8/10 (80%) +10% _+#
+ a/b - removed ==
+ a/c 10/10 (100%) new #
+`, normalize(buf.String()))
+	})
+
+	t.Run("limited number of runs, with a different metric", func(t *testing.T) {
+		buf := new(bytes.Buffer)
+		WriteTrend(buf, records, EnforcerOptions{TrendRuns: 1, Metric: metricLines})
+		assert.Equal(t, `Latest run: 2020-01-03 12:00:00 UTC (0123456789ab)
+Previous run: none
+Showing lines coverage for the last 1 run(s)
+
+total 20/20 (100%) #
+ a 10/10 (100%) #
+ a/c 10/10 (100%) #
+`, normalize(buf.String()))
+	})
+
+	t.Run("no runs", func(t *testing.T) {
+		buf := new(bytes.Buffer)
+		WriteTrend(buf, nil, EnforcerOptions{})
+		assert.Equal(t, "No runs have been recorded\n", buf.String())
+	})
+}
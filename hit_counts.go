@@ -0,0 +1,59 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"sort"
+)
+
+// getColdAndHotBlocks finds the blocks to be listed by the "-showcold" and "-showhot" options. The
+// cold blocks are the covered blocks that were executed at most opts.ColdBlockHits times, in
+// ascending order of execution count; the hot blocks are the opts.HotBlockCount blocks that were
+// executed the most times, in descending order of execution count. Skipped blocks are not included.
+func getColdAndHotBlocks(result AnalyzerResult, opts EnforcerOptions) (cold, hot []CodeBlockCoverage) {
+	if opts.ColdBlockHits <= 0 && opts.HotBlockCount <= 0 {
+		return nil, nil
+	}
+	var all []CodeBlockCoverage
+	for _, p := range result.Packages {
+		for _, f := range p.Files {
+			for _, b := range f.Blocks {
+				if isCovered(b.CoverageCount, getMinHits(opts)) {
+					all = append(all, b)
+				}
+			}
+		}
+	}
+
+	if opts.ColdBlockHits > 0 {
+		for _, b := range all {
+			if b.CoverageCount <= opts.ColdBlockHits {
+				cold = append(cold, b)
+			}
+		}
+		sort.SliceStable(cold, func(i, j int) bool { return cold[i].CoverageCount < cold[j].CoverageCount })
+	}
+
+	if opts.HotBlockCount > 0 {
+		hot = append(hot, all...)
+		sort.SliceStable(hot, func(i, j int) bool { return hot[i].CoverageCount > hot[j].CoverageCount })
+		if len(hot) > opts.HotBlockCount {
+			hot = hot[:opts.HotBlockCount]
+		}
+	}
+	return cold, hot
+}
+
+func writeBlockHitCounts(writer io.Writer, blocks []CodeBlockCoverage) {
+	for _, b := range blocks {
+		fmt.Fprintf(writer, "%s %d-%d: executed %s\n",
+			b.CodeRange.FilePath, b.CodeRange.StartLine, b.CodeRange.EndLine, describeHitCount(b.CoverageCount))
+	}
+}
+
+func describeHitCount(n int) string {
+	if n == 1 {
+		return "1 time"
+	}
+	return fmt.Sprintf("%d times", n)
+}
@@ -0,0 +1,89 @@
+package main
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testDataCountsFile = "coverage_data_with_counts"
+
+func TestAnalyzeCoverageWithMinHits(t *testing.T) {
+	t.Run("blocks with too few hits are uncovered", func(t *testing.T) {
+		withValidTestProfile(testDataCountsFile, func(cp *CoverageProfile) {
+			opts := testBaseOptions
+			opts.MinHits = 3
+			result, err := AnalyzeCoverage(cp, opts)
+			require.NoError(t, err)
+
+			f := result.Packages[0].Files[0]
+			assert.Equal(t, 7, f.TotalStatements)
+			assert.Equal(t, 3, f.CoveredStatements)
+			var counts []int
+			for _, b := range f.UncoveredBlocks {
+				counts = append(counts, b.CoverageCount)
+			}
+			assert.Equal(t, []int{1, 2, 0, 2}, counts)
+		})
+	})
+
+	t.Run("default is one hit", func(t *testing.T) {
+		withValidTestProfile(testDataCountsFile, func(cp *CoverageProfile) {
+			result, err := AnalyzeCoverage(cp, testBaseOptions)
+			require.NoError(t, err)
+
+			f := result.Packages[0].Files[0]
+			assert.Equal(t, 6, f.CoveredStatements)
+			require.Len(t, f.UncoveredBlocks, 1)
+			assert.Equal(t, 0, f.UncoveredBlocks[0].CoverageCount)
+		})
+	})
+
+	t.Run("set mode is rejected", func(t *testing.T) {
+		for _, opts := range []EnforcerOptions{
+			{PackagePath: testDataPackagePath, MinHits: 2},
+			{PackagePath: testDataPackagePath, ColdBlockHits: 2},
+			{PackagePath: testDataPackagePath, HotBlockCount: 2},
+		} {
+			withValidTestProfile(testDataFuncsFile, func(cp *CoverageProfile) {
+				_, err := AnalyzeCoverage(cp, opts)
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), `this profile's mode is "set"`)
+			})
+		}
+	})
+}
+
+func TestReportOutputWithHitCounts(t *testing.T) {
+	withValidTestProfile(testDataCountsFile, func(cp *CoverageProfile) {
+		opts := testBaseOptions
+		opts.MinHits = 2
+		opts.ColdBlockHits = 2
+		opts.HotBlockCount = 2
+		result, err := AnalyzeCoverage(cp, opts)
+		require.NoError(t, err)
+		report := NewSummaryReport(result, opts)
+
+		buf := new(bytes.Buffer)
+		report.Output(buf, opts)
+		assert.Equal(t, `Cold blocks (executed at most 2 times):
+base-package/gosource/sample.go 16-17: executed 2 times
+base-package/gosource/sample.go 20-21: executed 2 times
+
+Hot blocks (most frequently executed):
+base-package/gosource/sample.go 3-5: executed 150 times
+base-package/gosource/sample.go 9-10: executed 5 times
+
+Uncovered blocks detected:
+base-package/gosource/sample.go 10-12 (in T.Inc) (executed 1 time, minimum is 2)
+base-package/gosource/sample.go 17-19 (in T.Get)
+`, buf.String())
+	})
+}
+
+func TestDescribeHitCount(t *testing.T) {
+	assert.Equal(t, "1 time", describeHitCount(1))
+	assert.Equal(t, "2 times", describeHitCount(2))
+}
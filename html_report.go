@@ -0,0 +1,250 @@
+package main
+
+import (
+	"fmt"
+	"html/template"
+	"io"
+	"io/ioutil"
+	"path"
+	"strings"
+)
+
+type htmlReportData struct {
+	PackagePath  string
+	Pass         bool
+	Rules        []SummaryReportRule
+	Coverage     SummaryReportCoverage
+	Packages     []htmlPackage
+	SkippedFiles []htmlSkippedFile
+}
+
+type htmlPackage struct {
+	Path     string
+	Pass     bool
+	Coverage SummaryReportCoverage
+	Files    []htmlFile
+}
+
+type htmlFile struct {
+	ID          string
+	Path        string
+	Pass        bool
+	Coverage    SummaryReportCoverage
+	SourceError string
+	Lines       []htmlLine
+}
+
+type htmlLine struct {
+	Number   int
+	Segments []htmlSegment
+}
+
+// htmlSegment is a run of source text on a single line that has the same coverage status.
+type htmlSegment struct {
+	Text  string
+	Class string
+	Title string
+}
+
+type htmlSkippedFile struct {
+	FilePath string
+	Reason   string
+}
+
+// WriteHTMLReport writes the report data as a self-contained HTML page. The page shows the outcome
+// of each rule, the coverage of each package and file, and the source code of each file, with
+// covered, uncovered, and skipped code highlighted according to the exact column ranges in the
+// profile. Skipped code has a tooltip describing which option caused it to be skipped.
+//
+// The source files are read from paths relative to the current directory; if a file cannot be read,
+// the page says so instead of showing its source code.
+func WriteHTMLReport(writer io.Writer, report SummaryReport, result AnalyzerResult, opts EnforcerOptions) error {
+	data := htmlReportData{
+		PackagePath: opts.PackagePath,
+		Pass:        report.Pass,
+		Rules:       report.Rules,
+		Coverage:    report.GetTotalCoverage(),
+	}
+
+	minHits := getMinHits(opts)
+	skippedBlocksByFile := make(map[string][]SkippedBlock)
+	for _, b := range result.SkippedBlocks {
+		skippedBlocksByFile[b.CodeRange.FilePath] = append(skippedBlocksByFile[b.CodeRange.FilePath], b)
+	}
+
+	packageResults := getPackageResultsByPath(result, opts)
+	for _, rp := range report.Packages {
+		p := packageResults[rp.FullPackagePath]
+		hp := htmlPackage{Path: rp.FullPackagePath, Pass: true, Coverage: rp.Coverage}
+		for i, f := range p.Files {
+			hf := htmlFile{
+				ID:       fmt.Sprintf("p%d-f%d", len(data.Packages)+1, i+1),
+				Path:     path.Join(p.RelativePath, f.FileName),
+				Pass:     len(f.UncoveredBlocks) == 0,
+				Coverage: rp.Files[i].Coverage,
+			}
+			hp.Pass = hp.Pass && hf.Pass
+
+			source, err := ioutil.ReadFile(hf.Path)
+			if err != nil {
+				hf.SourceError = err.Error()
+			} else {
+				var annotations []htmlSegment
+				var ranges []CodeRange
+				for _, b := range f.Blocks {
+					switch {
+					case isCovered(b.CoverageCount, minHits):
+						annotations = append(annotations, htmlSegment{Class: "covered",
+							Title: fmt.Sprintf("covered (count: %d)", b.CoverageCount)})
+					case b.CoverageCount > 0:
+						annotations = append(annotations, htmlSegment{Class: "uncovered",
+							Title: fmt.Sprintf("not covered enough (count: %d, minimum: %d)", b.CoverageCount, minHits)})
+					default:
+						annotations = append(annotations, htmlSegment{Class: "uncovered", Title: "not covered"})
+					}
+					ranges = append(ranges, b.CodeRange)
+				}
+				for _, b := range skippedBlocksByFile[rp.FullPackagePath+"/"+f.FileName] {
+					annotations = append(annotations, htmlSegment{Class: "skipped", Title: describeSkipReason(b.Reason)})
+					ranges = append(ranges, b.CodeRange)
+				}
+				hf.Lines = makeHTMLLines(strings.Split(string(source), "\n"), ranges, annotations)
+			}
+			hp.Files = append(hp.Files, hf)
+		}
+		data.Packages = append(data.Packages, hp)
+	}
+
+	for _, filePath := range result.SkippedFilePaths {
+		data.SkippedFiles = append(data.SkippedFiles, htmlSkippedFile{
+			FilePath: filePath,
+			Reason:   describeSkipReason(SkipReason{Option: "skipfiles", Pattern: opts.SkipFilesPattern.String()}),
+		})
+	}
+
+	return htmlReportTemplate.Execute(writer, data)
+}
+
+// makeHTMLLines divides each source line into segments according to which code range, if any,
+// each column belongs to. If ranges overlap, the one that appears later in the list takes precedence.
+func makeHTMLLines(sourceLines []string, ranges []CodeRange, annotations []htmlSegment) []htmlLine {
+	if len(sourceLines) > 0 && sourceLines[len(sourceLines)-1] == "" {
+		sourceLines = sourceLines[:len(sourceLines)-1]
+	}
+
+	// columnOwners[i][j] is 1 + the index of the range that owns column j+1 of line i+1, or 0 if none
+	columnOwners := make([][]int, len(sourceLines))
+	for i, line := range sourceLines {
+		columnOwners[i] = make([]int, len(line))
+	}
+	for n, r := range ranges {
+		for line := r.StartLine; line <= r.EndLine && line <= len(sourceLines); line++ {
+			owners := columnOwners[line-1]
+			start, end := 1, len(owners)+1
+			if line == r.StartLine {
+				start = r.StartColumn
+			}
+			if line == r.EndLine && r.EndColumn < end {
+				end = r.EndColumn
+			}
+			for col := start; col < end; col++ {
+				owners[col-1] = n + 1
+			}
+		}
+	}
+
+	ret := make([]htmlLine, 0, len(sourceLines))
+	for i, line := range sourceLines {
+		hl := htmlLine{Number: i + 1}
+		owners := columnOwners[i]
+		for start := 0; start < len(line); {
+			end := start + 1
+			for end < len(line) && owners[end] == owners[start] {
+				end++
+			}
+			var seg htmlSegment
+			if owners[start] > 0 {
+				seg = annotations[owners[start]-1]
+			}
+			seg.Text = line[start:end]
+			hl.Segments = append(hl.Segments, seg)
+			start = end
+		}
+		ret = append(ret, hl)
+	}
+	return ret
+}
+
+var htmlReportTemplate = template.Must(template.New("report").Parse(`<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<title>Coverage report: {{.PackagePath}}</title>
+<style>
+body { font-family: sans-serif; margin: 1em 2em; }
+table.stats { border-collapse: collapse; margin-bottom: 1em; }
+table.stats td, table.stats th { padding: 2px 12px; text-align: left; }
+table.stats td.num { text-align: right; }
+tr.package { font-weight: bold; }
+tr.file td:first-child { padding-left: 32px; }
+.pass { color: #080; }
+.fail { color: #c00; }
+details.source { margin: 0.5em 0; }
+details.source summary { cursor: pointer; font-family: monospace; }
+table.source { border-collapse: collapse; font-family: monospace; font-size: 13px; }
+table.source td { white-space: pre; padding: 0 8px; vertical-align: top; }
+table.source td.ln { color: #888; text-align: right; user-select: none; }
+span.covered { background: #cfc; }
+span.uncovered { background: #fcc; }
+span.skipped { background: #ddd; color: #666; }
+</style>
+</head>
+<body>
+<h1>Coverage report: {{.PackagePath}}</h1>
+<p class="{{if .Pass}}pass{{else}}fail{{end}}">{{if .Pass}}Coverage scan passes!{{else}}Coverage scan failed.{{end}}
+Total: {{.Coverage.CoveredStatements}}/{{.Coverage.TotalStatements}} statements ({{.Coverage.GetCoveredPercent}}%)</p>
+<h2>Rules</h2>
+<ul>
+{{- range .Rules}}
+<li class="{{if .Pass}}pass{{else}}fail{{end}}">{{if .Pass}}&#x2714;{{else}}&#x2718;{{end}} {{.Name}}: {{.Message}}</li>
+{{- end}}
+</ul>
+<h2>Packages</h2>
+<table class="stats">
+<tr><th>Package / file</th><th>Statements</th><th>Coverage</th><th>Result</th></tr>
+{{- range .Packages}}
+<tr class="package"><td>{{.Path}}</td><td class="num">{{.Coverage.CoveredStatements}}/{{.Coverage.TotalStatements}}</td><td class="num">{{.Coverage.GetCoveredPercent}}%</td><td class="{{if .Pass}}pass{{else}}fail{{end}}">{{if .Pass}}pass{{else}}fail{{end}}</td></tr>
+{{- range .Files}}
+<tr class="file"><td><a href="#{{.ID}}">{{.Path}}</a></td><td class="num">{{.Coverage.CoveredStatements}}/{{.Coverage.TotalStatements}}</td><td class="num">{{.Coverage.GetCoveredPercent}}%</td><td class="{{if .Pass}}pass{{else}}fail{{end}}">{{if .Pass}}pass{{else}}fail{{end}}</td></tr>
+{{- end}}
+{{- end}}
+</table>
+{{- if .SkippedFiles}}
+<h2>Skipped files</h2>
+<ul>
+{{- range .SkippedFiles}}
+<li>{{.FilePath}}: {{.Reason}}</li>
+{{- end}}
+</ul>
+{{- end}}
+<h2>Source</h2>
+<p><span class="covered">covered</span> <span class="uncovered">not covered</span> <span class="skipped">skipped</span></p>
+{{- range .Packages}}
+{{- range .Files}}
+<details class="source" id="{{.ID}}"{{if not .Pass}} open{{end}}>
+<summary>{{.Path}} ({{.Coverage.GetCoveredPercent}}%)</summary>
+{{- if .SourceError}}
+<p>Source code is not available: {{.SourceError}}</p>
+{{- else}}
+<table class="source">
+{{- range .Lines}}
+<tr><td class="ln">{{.Number}}</td><td>{{range .Segments}}{{if .Class}}<span class="{{.Class}}" title="{{.Title}}">{{.Text}}</span>{{else}}{{.Text}}{{end}}{{end}}</td></tr>
+{{- end}}
+</table>
+{{- end}}
+</details>
+{{- end}}
+{{- end}}
+</body>
+</html>
+`))
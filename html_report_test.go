@@ -0,0 +1,72 @@
+package main
+
+import (
+	"regexp"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHTMLReport(t *testing.T) {
+	t.Run("highlights covered, uncovered, and skipped code", func(t *testing.T) {
+		withValidTestProfile(testDataMainFile, func(cp *CoverageProfile) {
+			opts := testBaseOptions
+			opts.SkipFilesPattern = regexp.MustCompile("econ")
+			opts.SkipCodePattern = regexp.MustCompile("third.*1")
+			s := writeReportForTest(t, cp, opts, "html")
+
+			assert.Contains(t, s, `<li class="fail">&#x2718; uncovered-blocks: 4 uncovered block(s) detected</li>`)
+			assert.Contains(t, s, `<tr class="package"><td>base-package</td><td class="num">2/7</td><td class="num">28%</td><td class="fail">fail</td></tr>`)
+			assert.Contains(t, s, `<tr class="file"><td><a href="#p1-f2">third</a></td><td class="num">2/4</td><td class="num">50%</td><td class="fail">fail</td></tr>`)
+			assert.Contains(t, s, `<li>base-package/second: skipped by -skipfiles &#34;econ&#34;</li>`)
+
+			assert.Contains(t, s, `<tr><td class="ln">3</td><td>fir<span class="uncovered" title="not covered">st file line 3</span></td></tr>`)
+			assert.Contains(t, s, `<tr><td class="ln">5</td><td><span class="uncovered" title="not covered">f</span>irst file line 5</td></tr>`)
+			assert.Contains(t, s, `<tr><td class="ln">1</td><td><span class="skipped" title="skipped by -skipcode &#34;third.*1&#34; (line 1: third file line 1)">third file line 1</span></td></tr>`)
+			assert.Contains(t, s, `<tr><td class="ln">3</td><td><span class="covered" title="covered (count: 1)">third file line 3</span></td></tr>`)
+			assert.Contains(t, s, `<tr><td class="ln">2</td><td><span class="uncovered" title="not covered">other pac</span>kage first file line 2</td></tr>`)
+		})
+	})
+
+	t.Run("uncovered code with -mergeblocks", func(t *testing.T) {
+		withValidTestProfile(testDataFuncsFile, func(cp *CoverageProfile) {
+			opts := testBaseOptions
+			opts.MergeBlocks = true
+			s := writeReportForTest(t, cp, opts, "html")
+
+			assert.Contains(t, s, "<tr><td class=\"ln\">11</td><td><span class=\"uncovered\" title=\"not covered\">\t\tpanic(&#34;negative&#34;)</span></td></tr>")
+			assert.Contains(t, s, "<tr><td class=\"ln\">20</td><td>\t<span class=\"uncovered\" title=\"not covered\">return f()</span></td></tr>")
+			assert.NotContains(t, s, `title="covered (count: 0)"`)
+		})
+	})
+
+	t.Run("blocks with too few hits", func(t *testing.T) {
+		withValidTestProfile(testDataCountsFile, func(cp *CoverageProfile) {
+			opts := testBaseOptions
+			opts.MinHits = 3
+			s := writeReportForTest(t, cp, opts, "html")
+
+			assert.Contains(t, s, "<tr><td class=\"ln\">11</td><td><span class=\"uncovered\" title=\"not covered enough (count: 1, minimum: 3)\">\t\tpanic(&#34;negative&#34;)</span></td></tr>")
+			assert.Contains(t, s, "<tr><td class=\"ln\">18</td><td><span class=\"uncovered\" title=\"not covered\">\t\treturn t.n</span></td></tr>")
+			assert.Contains(t, s, "<tr><td class=\"ln\">4</td><td><span class=\"covered\" title=\"covered (count: 150)\">\treturn a &#43; b</span></td></tr>")
+		})
+	})
+
+	t.Run("passing report", func(t *testing.T) {
+		withValidTestProfile(testDataReportPassFile, func(cp *CoverageProfile) {
+			s := writeReportForTest(t, cp, testBaseOptions, "html")
+
+			assert.Contains(t, s, `<p class="pass">Coverage scan passes!`)
+			assert.Contains(t, s, `<details class="source" id="p1-f1">`)
+		})
+	})
+
+	t.Run("source file not found", func(t *testing.T) {
+		withValidTestProfile("coverage_data_with_bad_filename", func(cp *CoverageProfile) {
+			s := writeReportForTest(t, cp, testBaseOptions, "html")
+
+			assert.Contains(t, s, `<summary>nonexistent_file (0%)</summary>
+<p>Source code is not available: `)
+		})
+	})
+}
@@ -0,0 +1,341 @@
+package main
+
+import (
+	"encoding/json"
+	"io"
+	"path"
+)
+
+// JSONReportSchemaVersion is the version of the data format written by "-format json". It will only
+// be incremented if a field is removed or its meaning is changed; new fields may be added without
+// changing the version.
+const JSONReportSchemaVersion = 1
+
+// JSONReport is the top-level object written by "-format json".
+type JSONReport struct {
+	// SchemaVersion is always equal to JSONReportSchemaVersion.
+	SchemaVersion int `json:"schemaVersion"`
+
+	// PackagePath is the base import path of the package that was analyzed.
+	PackagePath string `json:"packagePath"`
+
+	// Pass is true if the coverage scan passed, meaning that every rule passed.
+	Pass bool `json:"pass"`
+
+	// Rules contains the outcome of each of the checks that determine whether the scan passed.
+	Rules []JSONRule `json:"rules"`
+
+	// Coverage is the total coverage of all packages, after filtering.
+	Coverage JSONCoverage `json:"coverage"`
+
+	// Packages contains the results for each analyzed package, sorted by import path. It does not
+	// include packages whose files were all skipped with "-skipfiles".
+	Packages []JSONPackage `json:"packages"`
+
+	// SkippedFiles is a list of the files that were skipped with "-skipfiles".
+	SkippedFiles []JSONSkippedFile `json:"skippedFiles"`
+
+	// SkippedBlocks is a list of the code blocks that were skipped with either "-skipfiles" or
+	// "-skipcode".
+	SkippedBlocks []JSONSkippedBlock `json:"skippedBlocks"`
+
+	// StaleRanges is a list of the code ranges in the profile that do not match the current source
+	// code. It is always empty if the check was disabled with "-stale ignore".
+	StaleRanges []JSONStaleRange `json:"staleRanges"`
+}
+
+// JSONRule is the outcome of a single check in JSONReport.
+type JSONRule struct {
+	// Name is a short identifier for the rule, such as "uncovered-blocks".
+	Name string `json:"name"`
+
+	// Pass is true if the rule was satisfied.
+	Pass bool `json:"pass"`
+
+	// Message is a human-readable description of the outcome.
+	Message string `json:"message"`
+}
+
+// JSONCoverage describes the coverage of a package, a file, or the whole report.
+type JSONCoverage struct {
+	// TotalStatements is the number of statements that were counted, not including skipped code.
+	TotalStatements int `json:"totalStatements"`
+
+	// CoveredStatements is the number of those statements that were covered.
+	CoveredStatements int `json:"coveredStatements"`
+
+	// Percent is the percentage of statements that were covered, rounded down. It is 100 if there
+	// were no statements.
+	Percent int `json:"percent"`
+
+	// TotalLines is the number of source lines that contain any part of a block, not including
+	// skipped code.
+	TotalLines int `json:"totalLines"`
+
+	// CoveredLines is the number of those lines for which every block containing any part of the
+	// line was covered.
+	CoveredLines int `json:"coveredLines"`
+
+	// TotalBlocks is the number of code blocks, not including skipped code.
+	TotalBlocks int `json:"totalBlocks"`
+
+	// CoveredBlocks is the number of those blocks that were covered.
+	CoveredBlocks int `json:"coveredBlocks"`
+}
+
+// JSONPackage is package-level information in JSONReport.
+type JSONPackage struct {
+	// Path is the full import path of the package.
+	Path string `json:"path"`
+
+	// RelativePath is the package's path relative to the base package; it is "" for the base
+	// package itself.
+	RelativePath string `json:"relativePath"`
+
+	// Pass is true if no file in this package had uncovered blocks.
+	Pass bool `json:"pass"`
+
+	// Coverage is the total coverage of the files in this package.
+	Coverage JSONCoverage `json:"coverage"`
+
+	// Files contains the results for each analyzed file in this package.
+	Files []JSONFile `json:"files"`
+}
+
+// JSONFile is file-level information in JSONReport.
+type JSONFile struct {
+	// FileName is the simple filename, without the package path.
+	FileName string `json:"fileName"`
+
+	// Path is the file's path relative to the base package directory.
+	Path string `json:"path"`
+
+	// Pass is true if the file had no uncovered blocks.
+	Pass bool `json:"pass"`
+
+	// Coverage is the coverage of this file.
+	Coverage JSONCoverage `json:"coverage"`
+
+	// Functions contains the coverage of each function in this file that contains at least one
+	// block. It is omitted if the file could not be parsed as Go source code.
+	Functions []JSONFunction `json:"functions,omitempty"`
+
+	// UncoveredBlocks are the code blocks in this file that lacked coverage, in ascending order of
+	// starting line number.
+	UncoveredBlocks []JSONUncoveredBlock `json:"uncoveredBlocks"`
+
+	// PartialLines are the numbers of the lines in this file that contain parts of both covered and
+	// uncovered blocks, in ascending order. It is omitted if there are none.
+	PartialLines []int `json:"partialLines,omitempty"`
+}
+
+// JSONFunction is function-level information in JSONReport.
+type JSONFunction struct {
+	// Name is the name of the function. For methods, it is prefixed with the receiver type, as in
+	// "TypeName.MethodName".
+	Name string `json:"name"`
+
+	// StartLine is the line number where the function declaration begins.
+	StartLine int `json:"startLine"`
+
+	// Coverage is the coverage of this function, including any function literals within it.
+	Coverage JSONCoverage `json:"coverage"`
+}
+
+// JSONCodeRange describes a section of source code. Line and column numbers start at 1.
+type JSONCodeRange struct {
+	// FilePath is the path of the source file as it appears in the coverage profile, including
+	// the package's import path.
+	FilePath string `json:"filePath"`
+
+	StartLine   int `json:"startLine"`
+	StartColumn int `json:"startColumn"`
+	EndLine     int `json:"endLine"`
+	EndColumn   int `json:"endColumn"`
+}
+
+// JSONUncoveredBlock is a code block that had no coverage.
+type JSONUncoveredBlock struct {
+	// Range is the location of the block.
+	Range JSONCodeRange `json:"range"`
+
+	// Statements is the number of statements in the block.
+	Statements int `json:"statements"`
+
+	// Hits is the number of times the block was executed, if it was executed fewer times than the
+	// minimum specified with "-minhits". It is omitted if the block was not executed at all.
+	Hits int `json:"hits,omitempty"`
+
+	// Function is the name of the function containing the block, in the same format as
+	// JSONFunction.Name. It is omitted if the source file could not be parsed.
+	Function string `json:"function,omitempty"`
+
+	// Text is the source code from the block's starting line to its ending line. It is only
+	// provided if the "-showcode" option was used.
+	Text []string `json:"text,omitempty"`
+}
+
+// JSONSkippedFile is a file that was skipped with "-skipfiles".
+type JSONSkippedFile struct {
+	// FilePath is the path of the source file as it appears in the coverage profile.
+	FilePath string `json:"filePath"`
+
+	// Reason describes why the file was skipped.
+	Reason JSONSkipReason `json:"reason"`
+}
+
+// JSONSkippedBlock is a code block that was skipped with "-skipfiles" or "-skipcode".
+type JSONSkippedBlock struct {
+	// Range is the location of the block.
+	Range JSONCodeRange `json:"range"`
+
+	// Statements is the number of statements in the block.
+	Statements int `json:"statements"`
+
+	// Covered is true if the block was reported as covered in the coverage profile.
+	Covered bool `json:"covered"`
+
+	// Reason describes why the block was skipped.
+	Reason JSONSkipReason `json:"reason"`
+}
+
+// JSONSkipReason describes why a file or code block was skipped.
+type JSONSkipReason struct {
+	// Option is the name of the option that caused the skip: "skipfiles" or "skipcode".
+	Option string `json:"option"`
+
+	// Pattern is the regular expression that was specified for that option.
+	Pattern string `json:"pattern"`
+
+	// Line is the line number of the source line that matched "-skipcode". It is omitted for
+	// "-skipfiles".
+	Line int `json:"line,omitempty"`
+
+	// Text is the source line that matched "-skipcode". It is omitted for "-skipfiles".
+	Text string `json:"text,omitempty"`
+}
+
+// JSONStaleRange is a code range in the coverage profile that does not match the current source
+// code, as described for "-stale".
+type JSONStaleRange struct {
+	// Range is the location of the code range in the profile.
+	Range JSONCodeRange `json:"range"`
+
+	// Reason is a human-readable description of the mismatch.
+	Reason string `json:"reason"`
+}
+
+// NewJSONReport combines the information from SummaryReport and AnalyzerResult into the data that is
+// written by "-format json".
+func NewJSONReport(report SummaryReport, result AnalyzerResult, opts EnforcerOptions) JSONReport {
+	jr := JSONReport{
+		SchemaVersion: JSONReportSchemaVersion,
+		PackagePath:   opts.PackagePath,
+		Pass:          report.Pass,
+		Rules:         make([]JSONRule, 0, len(report.Rules)),
+		Coverage:      makeJSONCoverage(report.GetTotalCoverage()),
+		Packages:      make([]JSONPackage, 0, len(report.Packages)),
+		SkippedFiles:  make([]JSONSkippedFile, 0, len(result.SkippedFilePaths)),
+		SkippedBlocks: make([]JSONSkippedBlock, 0, len(result.SkippedBlocks)),
+		StaleRanges:   make([]JSONStaleRange, 0, len(result.StaleRanges)),
+	}
+
+	for _, rule := range report.Rules {
+		jr.Rules = append(jr.Rules, JSONRule{Name: rule.Name, Pass: rule.Pass, Message: rule.Message})
+	}
+
+	packageResults := getPackageResultsByPath(result, opts)
+	for _, rp := range report.Packages {
+		p := packageResults[rp.FullPackagePath]
+		jp := JSONPackage{
+			Path:         rp.FullPackagePath,
+			RelativePath: p.RelativePath,
+			Pass:         true,
+			Coverage:     makeJSONCoverage(rp.Coverage),
+			Files:        make([]JSONFile, 0, len(p.Files)),
+		}
+		for i, f := range p.Files {
+			jf := JSONFile{
+				FileName:        f.FileName,
+				Path:            path.Join(p.RelativePath, f.FileName),
+				Pass:            len(f.UncoveredBlocks) == 0,
+				Coverage:        makeJSONCoverage(rp.Files[i].Coverage),
+				UncoveredBlocks: make([]JSONUncoveredBlock, 0, len(f.UncoveredBlocks)),
+				PartialLines:    getPartialLines(f.Blocks, getMinHits(opts)),
+			}
+			for _, b := range f.UncoveredBlocks {
+				jf.UncoveredBlocks = append(jf.UncoveredBlocks, JSONUncoveredBlock{
+					Range:      makeJSONCodeRange(b.CodeRange),
+					Statements: b.StatementCount,
+					Hits:       b.CoverageCount,
+					Function:   b.FunctionName,
+					Text:       b.Text,
+				})
+			}
+			for _, fn := range rp.Files[i].Functions {
+				jf.Functions = append(jf.Functions, JSONFunction{
+					Name:      fn.Name,
+					StartLine: fn.StartLine,
+					Coverage:  makeJSONCoverage(fn.Coverage),
+				})
+			}
+			jp.Pass = jp.Pass && jf.Pass
+			jp.Files = append(jp.Files, jf)
+		}
+		jr.Packages = append(jr.Packages, jp)
+	}
+
+	for _, filePath := range result.SkippedFilePaths {
+		jr.SkippedFiles = append(jr.SkippedFiles, JSONSkippedFile{
+			FilePath: filePath,
+			Reason:   JSONSkipReason{Option: "skipfiles", Pattern: opts.SkipFilesPattern.String()},
+		})
+	}
+	for _, b := range result.SkippedBlocks {
+		jr.SkippedBlocks = append(jr.SkippedBlocks, JSONSkippedBlock{
+			Range:      makeJSONCodeRange(b.CodeRange),
+			Statements: b.StatementCount,
+			Covered:    isCovered(b.CoverageCount, getMinHits(opts)),
+			Reason: JSONSkipReason{
+				Option:  b.Reason.Option,
+				Pattern: b.Reason.Pattern,
+				Line:    b.Reason.Line,
+				Text:    b.Reason.Text,
+			},
+		})
+	}
+	for _, sr := range result.StaleRanges {
+		jr.StaleRanges = append(jr.StaleRanges, JSONStaleRange{Range: makeJSONCodeRange(sr.CodeRange), Reason: sr.Reason})
+	}
+
+	return jr
+}
+
+// WriteJSONReport writes the report data in JSON format, as described by JSONReport.
+func WriteJSONReport(writer io.Writer, report SummaryReport, result AnalyzerResult, opts EnforcerOptions) error {
+	enc := json.NewEncoder(writer)
+	enc.SetIndent("", "  ")
+	return enc.Encode(NewJSONReport(report, result, opts))
+}
+
+func makeJSONCoverage(c SummaryReportCoverage) JSONCoverage {
+	return JSONCoverage{
+		TotalStatements:   c.TotalStatements,
+		CoveredStatements: c.CoveredStatements,
+		Percent:           c.GetCoveredPercent(),
+		TotalLines:        c.TotalLines,
+		CoveredLines:      c.CoveredLines,
+		TotalBlocks:       c.TotalBlocks,
+		CoveredBlocks:     c.CoveredBlocks,
+	}
+}
+
+func makeJSONCodeRange(r CodeRange) JSONCodeRange {
+	return JSONCodeRange{
+		FilePath:    r.FilePath,
+		StartLine:   r.StartLine,
+		StartColumn: r.StartColumn,
+		EndLine:     r.EndLine,
+		EndColumn:   r.EndColumn,
+	}
+}
@@ -0,0 +1,150 @@
+package main
+
+import (
+	"encoding/json"
+	"regexp"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestJSONReport(t *testing.T) {
+	t.Run("report from data with coverage gaps", func(t *testing.T) {
+		withValidTestProfile(testDataReportNotPassFile, func(cp *CoverageProfile) {
+			opts := testBaseOptions
+			opts.ShowCode = true
+			jr := writeAndParseJSONReport(t, cp, opts)
+
+			assert.Equal(t, JSONReportSchemaVersion, jr.SchemaVersion)
+			assert.Equal(t, testDataPackagePath, jr.PackagePath)
+			assert.False(t, jr.Pass)
+			assert.Equal(t, []JSONRule{
+				{Name: uncoveredBlocksRuleName, Pass: false, Message: "2 uncovered block(s) detected"},
+			}, jr.Rules)
+			assert.Equal(t, JSONCoverage{TotalStatements: 15, CoveredStatements: 6, Percent: 40,
+				TotalLines: 12, CoveredLines: 9, TotalBlocks: 5, CoveredBlocks: 3}, jr.Coverage)
+
+			require.Len(t, jr.Packages, 2)
+			p1 := jr.Packages[0]
+			assert.Equal(t, testDataPackagePath, p1.Path)
+			assert.Equal(t, "", p1.RelativePath)
+			assert.False(t, p1.Pass)
+			assert.Equal(t, JSONCoverage{TotalStatements: 8, CoveredStatements: 6, Percent: 75,
+				TotalLines: 10, CoveredLines: 9, TotalBlocks: 4, CoveredBlocks: 3}, p1.Coverage)
+			require.Len(t, p1.Files, 3)
+			assert.Equal(t, JSONFile{
+				FileName: "first",
+				Path:     "first",
+				Pass:     false,
+				Coverage: JSONCoverage{TotalStatements: 4, CoveredStatements: 2, Percent: 50,
+					TotalLines: 2, CoveredLines: 1, TotalBlocks: 2, CoveredBlocks: 1},
+				UncoveredBlocks: []JSONUncoveredBlock{
+					{
+						Range: JSONCodeRange{FilePath: testDataPackagePath + "/first",
+							StartLine: 3, StartColumn: 1, EndLine: 4, EndColumn: 1},
+						Statements: 2,
+						Text:       []string{"first file line 3", "first file line 4"},
+					},
+				},
+			}, p1.Files[0])
+			assert.True(t, p1.Files[1].Pass)
+			assert.Equal(t, []JSONUncoveredBlock{}, p1.Files[1].UncoveredBlocks)
+
+			p2 := jr.Packages[1]
+			assert.Equal(t, testDataPackagePath+"/otherpackage", p2.Path)
+			assert.Equal(t, "otherpackage", p2.RelativePath)
+			require.Len(t, p2.Files, 1)
+			assert.Equal(t, "otherpackage/first", p2.Files[0].Path)
+
+			assert.Equal(t, []JSONSkippedFile{}, jr.SkippedFiles)
+			assert.Equal(t, []JSONSkippedBlock{}, jr.SkippedBlocks)
+			assert.Equal(t, []JSONStaleRange{}, jr.StaleRanges)
+		})
+	})
+
+	t.Run("report from data with no coverage gaps", func(t *testing.T) {
+		withValidTestProfile(testDataReportPassFile, func(cp *CoverageProfile) {
+			jr := writeAndParseJSONReport(t, cp, testBaseOptions)
+
+			assert.True(t, jr.Pass)
+			assert.Equal(t, []JSONRule{
+				{Name: uncoveredBlocksRuleName, Pass: true, Message: "no uncovered blocks"},
+			}, jr.Rules)
+			require.Len(t, jr.Packages, 1)
+			assert.True(t, jr.Packages[0].Pass)
+		})
+	})
+
+	t.Run("skipped files and blocks", func(t *testing.T) {
+		withValidTestProfile(testDataMainFile, func(cp *CoverageProfile) {
+			opts := testBaseOptions
+			opts.SkipFilesPattern = regexp.MustCompile("econ")
+			opts.SkipCodePattern = regexp.MustCompile("third.*1")
+			jr := writeAndParseJSONReport(t, cp, opts)
+
+			assert.Equal(t, []JSONSkippedFile{
+				{
+					FilePath: testDataPackagePath + "/second",
+					Reason:   JSONSkipReason{Option: "skipfiles", Pattern: "econ"},
+				},
+			}, jr.SkippedFiles)
+			assert.Equal(t, []JSONSkippedBlock{
+				{
+					Range: JSONCodeRange{FilePath: testDataPackagePath + "/second",
+						StartLine: 1, StartColumn: 1, EndLine: 5, EndColumn: 1},
+					Statements: 5,
+					Reason:     JSONSkipReason{Option: "skipfiles", Pattern: "econ"},
+				},
+				{
+					Range: JSONCodeRange{FilePath: testDataPackagePath + "/third",
+						StartLine: 1, StartColumn: 1, EndLine: 2, EndColumn: 1},
+					Statements: 2,
+					Reason:     JSONSkipReason{Option: "skipcode", Pattern: "third.*1", Line: 1, Text: "third file line 1"},
+				},
+			}, jr.SkippedBlocks)
+		})
+	})
+
+	t.Run("hit counts below minimum", func(t *testing.T) {
+		withValidTestProfile(testDataCountsFile, func(cp *CoverageProfile) {
+			opts := testBaseOptions
+			opts.MinHits = 2
+			jr := writeAndParseJSONReport(t, cp, opts)
+
+			blocks := jr.Packages[0].Files[0].UncoveredBlocks
+			require.Len(t, blocks, 2)
+			assert.Equal(t, 1, blocks[0].Hits)
+			assert.Equal(t, 0, blocks[1].Hits)
+		})
+	})
+
+	t.Run("stale ranges", func(t *testing.T) {
+		withValidTestProfile(testDataDiffOldFile, func(cp *CoverageProfile) {
+			opts := testBaseOptions
+			opts.StaleMode = staleModeWarn
+			jr := writeAndParseJSONReport(t, cp, opts)
+
+			require.Len(t, jr.StaleRanges, 6)
+			assert.Equal(t, JSONStaleRange{
+				Range: JSONCodeRange{FilePath: testDataDiffFilePath,
+					StartLine: 7, StartColumn: 24, EndLine: 9, EndColumn: 2},
+				Reason: "column 24 is beyond the end of line 7",
+			}, jr.StaleRanges[0])
+		})
+	})
+
+	t.Run("partial lines", func(t *testing.T) {
+		withValidTestProfile(testDataFuncsFile, func(cp *CoverageProfile) {
+			jr := writeAndParseJSONReport(t, cp, testBaseOptions)
+
+			assert.Equal(t, []int{10}, jr.Packages[0].Files[0].PartialLines)
+		})
+	})
+}
+
+func writeAndParseJSONReport(t *testing.T, cp *CoverageProfile, opts EnforcerOptions) JSONReport {
+	var jr JSONReport
+	require.NoError(t, json.Unmarshal([]byte(writeReportForTest(t, cp, opts, "json")), &jr))
+	return jr
+}
@@ -0,0 +1,118 @@
+package main
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io"
+	"strings"
+)
+
+const junitRulesSuiteName = "go-coverage-enforcer"
+
+type junitTestSuites struct {
+	XMLName  xml.Name         `xml:"testsuites"`
+	Name     string           `xml:"name,attr"`
+	Tests    int              `xml:"tests,attr"`
+	Failures int              `xml:"failures,attr"`
+	Suites   []junitTestSuite `xml:"testsuite"`
+}
+
+type junitTestSuite struct {
+	Name      string          `xml:"name,attr"`
+	Tests     int             `xml:"tests,attr"`
+	Failures  int             `xml:"failures,attr"`
+	TestCases []junitTestCase `xml:"testcase"`
+}
+
+type junitTestCase struct {
+	ClassName string        `xml:"classname,attr"`
+	Name      string        `xml:"name,attr"`
+	Failure   *junitFailure `xml:"failure,omitempty"`
+}
+
+type junitFailure struct {
+	Message string `xml:"message,attr"`
+	Type    string `xml:"type,attr"`
+	Text    string `xml:",chardata"`
+}
+
+// WriteJUnitReport writes the report data as JUnit XML. Each package becomes a test suite and each
+// file becomes a test case, which fails if the file has any uncovered blocks. The outcome of each rule
+// is reported as a test case in an additional suite called "go-coverage-enforcer".
+func WriteJUnitReport(writer io.Writer, report SummaryReport, result AnalyzerResult, opts EnforcerOptions) error {
+	blocksByFile := make(map[string][]UncoveredBlock)
+	for _, b := range report.UncoveredBlocks {
+		blocksByFile[b.CodeRange.FilePath] = append(blocksByFile[b.CodeRange.FilePath], b)
+	}
+
+	root := junitTestSuites{Name: opts.PackagePath}
+	for _, p := range report.Packages {
+		suite := junitTestSuite{Name: p.FullPackagePath}
+		for _, f := range p.Files {
+			tc := junitTestCase{ClassName: p.FullPackagePath, Name: f.FileName}
+			if blocks := blocksByFile[p.FullPackagePath+"/"+f.FileName]; len(blocks) > 0 {
+				tc.Failure = &junitFailure{
+					Message: fmt.Sprintf("%d uncovered block(s), %d/%d statements covered (%d%%)",
+						len(blocks),
+						f.Coverage.CoveredStatements,
+						f.Coverage.TotalStatements,
+						f.Coverage.GetCoveredPercent(),
+					),
+					Type: uncoveredBlocksRuleName,
+					Text: formatJUnitUncoveredBlocks(blocks),
+				}
+			}
+			suite.addTestCase(tc)
+		}
+		root.addTestSuite(suite)
+	}
+
+	rulesSuite := junitTestSuite{Name: junitRulesSuiteName}
+	for _, rule := range report.Rules {
+		tc := junitTestCase{ClassName: junitRulesSuiteName, Name: rule.Name}
+		if !rule.Pass {
+			tc.Failure = &junitFailure{Message: rule.Message, Type: rule.Name}
+		}
+		rulesSuite.addTestCase(tc)
+	}
+	root.addTestSuite(rulesSuite)
+
+	if _, err := io.WriteString(writer, xml.Header); err != nil {
+		return err
+	}
+	enc := xml.NewEncoder(writer)
+	enc.Indent("", "  ")
+	if err := enc.Encode(root); err != nil {
+		return err
+	}
+	_, err := io.WriteString(writer, "\n")
+	return err
+}
+
+func (s *junitTestSuite) addTestCase(tc junitTestCase) {
+	s.TestCases = append(s.TestCases, tc)
+	s.Tests++
+	if tc.Failure != nil {
+		s.Failures++
+	}
+}
+
+func (s *junitTestSuites) addTestSuite(suite junitTestSuite) {
+	s.Suites = append(s.Suites, suite)
+	s.Tests += suite.Tests
+	s.Failures += suite.Failures
+}
+
+func formatJUnitUncoveredBlocks(blocks []UncoveredBlock) string {
+	var sb strings.Builder
+	for i, b := range blocks {
+		if i > 0 && len(b.Text) > 0 {
+			sb.WriteString("\n")
+		}
+		fmt.Fprintf(&sb, "%s %d-%d\n", b.CodeRange.FilePath, b.CodeRange.StartLine, b.CodeRange.EndLine)
+		for j, line := range b.Text {
+			fmt.Fprintf(&sb, "%d>\t%s\n", b.CodeRange.StartLine+j, line)
+		}
+	}
+	return sb.String()
+}
@@ -0,0 +1,113 @@
+package main
+
+import (
+	"encoding/xml"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestJUnitReport(t *testing.T) {
+	t.Run("report from data with coverage gaps", func(t *testing.T) {
+		withValidTestProfile(testDataReportNotPassFile, func(cp *CoverageProfile) {
+			opts := testBaseOptions
+			opts.ShowCode = true
+			s, suites := writeAndParseJUnitReport(t, cp, opts)
+
+			assert.Contains(t, s, `<?xml version="1.0" encoding="UTF-8"?>`)
+			assert.Equal(t, testDataPackagePath, suites.Name)
+			assert.Equal(t, 5, suites.Tests)
+			assert.Equal(t, 3, suites.Failures)
+			require.Len(t, suites.Suites, 3)
+
+			s1 := suites.Suites[0]
+			assert.Equal(t, testDataPackagePath, s1.Name)
+			assert.Equal(t, 3, s1.Tests)
+			assert.Equal(t, 1, s1.Failures)
+			require.Len(t, s1.TestCases, 3)
+			assert.Equal(t, junitTestCase{
+				ClassName: testDataPackagePath,
+				Name:      "first",
+				Failure: &junitFailure{
+					Message: "1 uncovered block(s), 2/4 statements covered (50%)",
+					Type:    uncoveredBlocksRuleName,
+					Text: `base-package/first 3-4
+3>	first file line 3
+4>	first file line 4
+`,
+				},
+			}, s1.TestCases[0])
+			assert.Equal(t, junitTestCase{ClassName: testDataPackagePath, Name: "second"}, s1.TestCases[1])
+
+			s2 := suites.Suites[1]
+			assert.Equal(t, testDataPackagePath+"/otherpackage", s2.Name)
+			require.Len(t, s2.TestCases, 1)
+			require.NotNil(t, s2.TestCases[0].Failure)
+
+			s3 := suites.Suites[2]
+			assert.Equal(t, junitRulesSuiteName, s3.Name)
+			assert.Equal(t, []junitTestCase{
+				{
+					ClassName: junitRulesSuiteName,
+					Name:      uncoveredBlocksRuleName,
+					Failure:   &junitFailure{Message: "2 uncovered block(s) detected", Type: uncoveredBlocksRuleName},
+				},
+			}, s3.TestCases)
+		})
+	})
+
+	t.Run("report from data with no coverage gaps", func(t *testing.T) {
+		withValidTestProfile(testDataReportPassFile, func(cp *CoverageProfile) {
+			_, suites := writeAndParseJUnitReport(t, cp, testBaseOptions)
+
+			assert.Equal(t, 3, suites.Tests)
+			assert.Equal(t, 0, suites.Failures)
+			for _, suite := range suites.Suites {
+				for _, tc := range suite.TestCases {
+					assert.Nil(t, tc.Failure)
+				}
+			}
+		})
+	})
+}
+
+func TestJUnitReportShowsCodeForEachBlock(t *testing.T) {
+	withValidTestProfile(testDataMainFile, func(cp *CoverageProfile) {
+		opts := testBaseOptions
+		opts.ShowCode = true
+		_, suites := writeAndParseJUnitReport(t, cp, opts)
+
+		require.NotNil(t, suites.Suites[0].TestCases[0].Failure)
+		assert.Equal(t, `base-package/first 1-2
+1>	first file line 1
+2>	first file line 2
+
+base-package/first 3-5
+3>	first file line 3
+4>	first file line 4
+5>	first file line 5
+`, suites.Suites[0].TestCases[0].Failure.Text)
+	})
+}
+
+func TestJUnitReportWriteError(t *testing.T) {
+	withValidTestProfile(testDataReportNotPassFile, func(cp *CoverageProfile) {
+		result, err := AnalyzeCoverage(cp, testBaseOptions)
+		require.NoError(t, err)
+		report := NewSummaryReport(result, testBaseOptions)
+
+		for _, n := range []int{0, len(xml.Header), len(xml.Header) + 100} {
+			err := WriteReport(&failingWriter{remaining: n}, "junit", report, result, testBaseOptions)
+			assert.Error(t, err, "write failing after %d bytes", n)
+		}
+	})
+}
+
+func writeAndParseJUnitReport(t *testing.T, cp *CoverageProfile, opts EnforcerOptions) (string, junitTestSuites) {
+	s := writeReportForTest(t, cp, opts, "junit")
+	var suites junitTestSuites
+	require.NoError(t, xml.Unmarshal([]byte(s), &suites))
+	suites.XMLName = xml.Name{}
+	return s, suites
+}
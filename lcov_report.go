@@ -0,0 +1,36 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"path"
+)
+
+// WriteLCOVReport writes the filtered coverage data as an LCOV tracefile. There is one record for
+// each analyzed file, using the file's path relative to the base package directory. The line hit
+// counts are computed from the deduplicated blocks as described for getLineHitCounts; any lines that
+// are only part of blocks that were skipped with "-skipfiles" or "-skipcode" are omitted, and lines
+// whose count is less than "-minhits" are reported as having no hits.
+func WriteLCOVReport(writer io.Writer, report SummaryReport, result AnalyzerResult, opts EnforcerOptions) error {
+	minHits := getMinHits(opts)
+	bw := bufio.NewWriter(writer)
+	for _, p := range result.Packages {
+		for _, f := range p.Files {
+			fmt.Fprintf(bw, "TN:\nSF:%s\n", path.Join(p.RelativePath, f.FileName))
+			linesHit := 0
+			lineHits := getLineHitCounts(f.Blocks)
+			for _, lh := range lineHits {
+				hits := lh.Hits
+				if isCovered(hits, minHits) {
+					linesHit++
+				} else {
+					hits = 0
+				}
+				fmt.Fprintf(bw, "DA:%d,%d\n", lh.Line, hits)
+			}
+			fmt.Fprintf(bw, "LF:%d\nLH:%d\nend_of_record\n", len(lineHits), linesHit)
+		}
+	}
+	return bw.Flush()
+}
@@ -0,0 +1,107 @@
+package main
+
+import (
+	"regexp"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLCOVReport(t *testing.T) {
+	t.Run("report from data with coverage gaps", func(t *testing.T) {
+		withValidTestProfile(testDataReportNotPassFile, func(cp *CoverageProfile) {
+			assert.Equal(t, `TN:
+SF:first
+DA:1,1
+DA:3,0
+LF:2
+LH:1
+end_of_record
+TN:
+SF:second
+DA:1,1
+DA:2,1
+DA:3,1
+DA:4,1
+LF:4
+LH:4
+end_of_record
+TN:
+SF:third
+DA:1,1
+DA:2,1
+DA:3,1
+DA:4,1
+LF:4
+LH:4
+end_of_record
+TN:
+SF:otherpackage/first
+DA:1,0
+DA:2,0
+LF:2
+LH:0
+end_of_record
+`, writeReportForTest(t, cp, testBaseOptions, "lcov"))
+		})
+	})
+
+	t.Run("skipped blocks are omitted", func(t *testing.T) {
+		withValidTestProfile(testDataMainFile, func(cp *CoverageProfile) {
+			opts := testBaseOptions
+			opts.SkipFilesPattern = regexp.MustCompile("econ")
+			opts.SkipCodePattern = regexp.MustCompile("third.*1")
+			assert.Equal(t, `TN:
+SF:first
+DA:1,0
+DA:3,0
+DA:4,0
+DA:5,0
+LF:4
+LH:0
+end_of_record
+TN:
+SF:third
+DA:3,1
+DA:4,0
+LF:2
+LH:1
+end_of_record
+TN:
+SF:otherpackage/first
+DA:2,0
+LF:1
+LH:0
+end_of_record
+`, writeReportForTest(t, cp, opts, "lcov"))
+		})
+	})
+
+	t.Run("hit counts below minimum", func(t *testing.T) {
+		withValidTestProfile(testDataCountsFile, func(cp *CoverageProfile) {
+			opts := testBaseOptions
+			opts.MinHits = 5
+			assert.Equal(t, `TN:
+SF:gosource/sample.go
+DA:3,150
+DA:4,150
+DA:5,150
+DA:9,5
+DA:10,0
+DA:11,0
+DA:12,0
+DA:13,0
+DA:14,0
+DA:16,0
+DA:17,0
+DA:18,0
+DA:19,0
+DA:20,0
+DA:21,0
+LF:15
+LH:4
+end_of_record
+`, writeReportForTest(t, cp, opts, "lcov"))
+		})
+	})
+}
@@ -0,0 +1,109 @@
+package main
+
+import "sort"
+
+// lineHitCount is the coverage count that was computed for a single source line.
+type lineHitCount struct {
+	Line int
+	Hits int
+}
+
+// getLines returns the line numbers that contain at least one column of the code range. The ending
+// column of a range is exclusive, so a range that ends at column 1 of a line does not include that
+// line.
+func (r CodeRange) getLines() []int {
+	var ret []int
+	for line := r.StartLine; line <= r.EndLine; line++ {
+		if line == r.EndLine {
+			if r.EndColumn <= 1 || (line == r.StartLine && r.EndColumn <= r.StartColumn) {
+				break
+			}
+		}
+		ret = append(ret, line)
+	}
+	return ret
+}
+
+// getMinHits returns the minimum coverage count for a block to be considered covered, as specified
+// with "-minhits". The command-line parser never allows a value below 1, so zero means the options
+// were not created from the command line, and the default of 1 applies.
+func getMinHits(opts EnforcerOptions) int {
+	if opts.MinHits == 0 {
+		return 1
+	}
+	return opts.MinHits
+}
+
+// isCovered returns true if a block or line with the given coverage count is considered covered,
+// given the minimum number of hits specified with "-minhits".
+func isCovered(count, minHits int) bool {
+	return count > 0 && count >= minHits
+}
+
+// getBlocksCoverage computes statement, line, and block coverage for a set of blocks from the same
+// file. Lines are counted as described for getLineHitCounts.
+func getBlocksCoverage(blocks []CodeBlockCoverage, minHits int) SummaryReportCoverage {
+	var c SummaryReportCoverage
+	for _, b := range blocks {
+		c.TotalStatements += b.StatementCount
+		c.TotalBlocks++
+		if isCovered(b.CoverageCount, minHits) {
+			c.CoveredStatements += b.StatementCount
+			c.CoveredBlocks++
+		}
+	}
+	for _, lh := range getLineHitCounts(blocks) {
+		c.TotalLines++
+		if isCovered(lh.Hits, minHits) {
+			c.CoveredLines++
+		}
+	}
+	return c
+}
+
+// getLineHitCounts computes a coverage count for each source line that is part of at least one of
+// the specified blocks, in ascending order of line number. If several blocks overlap the same line,
+// the line gets the lowest of their counts, so a line is only considered covered if every block on
+// that line was covered.
+func getLineHitCounts(blocks []CodeBlockCoverage) []lineHitCount {
+	hitsByLine := make(map[int]int)
+	for _, b := range blocks {
+		for _, line := range b.CodeRange.getLines() {
+			if hits, ok := hitsByLine[line]; !ok || b.CoverageCount < hits {
+				hitsByLine[line] = b.CoverageCount
+			}
+		}
+	}
+	ret := make([]lineHitCount, 0, len(hitsByLine))
+	for line, hits := range hitsByLine {
+		ret = append(ret, lineHitCount{Line: line, Hits: hits})
+	}
+	sort.Slice(ret, func(i, j int) bool { return ret[i].Line < ret[j].Line })
+	return ret
+}
+
+// getPartialLines returns the line numbers, in ascending order, of source lines that contain at
+// least one column of a covered block and at least one column of an uncovered block. This happens
+// when a line contains several blocks, as in "if a && b { return }", and only some of them were
+// executed.
+func getPartialLines(blocks []CodeBlockCoverage, minHits int) []int {
+	coveredLines := make(map[int]bool)
+	uncoveredLines := make(map[int]bool)
+	for _, b := range blocks {
+		for _, line := range b.CodeRange.getLines() {
+			if isCovered(b.CoverageCount, minHits) {
+				coveredLines[line] = true
+			} else {
+				uncoveredLines[line] = true
+			}
+		}
+	}
+	var ret []int
+	for line := range coveredLines {
+		if uncoveredLines[line] {
+			ret = append(ret, line)
+		}
+	}
+	sort.Ints(ret)
+	return ret
+}
@@ -0,0 +1,68 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCodeRangeGetLines(t *testing.T) {
+	assert.Equal(t, []int{3, 4, 5}, CodeRange{StartLine: 3, StartColumn: 4, EndLine: 5, EndColumn: 2}.getLines())
+	assert.Equal(t, []int{1}, CodeRange{StartLine: 1, StartColumn: 1, EndLine: 2, EndColumn: 1}.getLines())
+	assert.Equal(t, []int{2}, CodeRange{StartLine: 2, StartColumn: 1, EndLine: 2, EndColumn: 10}.getLines())
+	assert.Nil(t, CodeRange{StartLine: 2, StartColumn: 5, EndLine: 2, EndColumn: 5}.getLines())
+}
+
+func TestGetLineHitCounts(t *testing.T) {
+	blocks := []CodeBlockCoverage{
+		{CodeRange{"a", 1, 10, 3, 2}, 2, 4},
+		{CodeRange{"a", 3, 2, 4, 5}, 1, 0},
+		{CodeRange{"a", 6, 1, 6, 20}, 1, 1},
+	}
+	assert.Equal(t, []lineHitCount{
+		{Line: 1, Hits: 4},
+		{Line: 2, Hits: 4},
+		{Line: 3, Hits: 0},
+		{Line: 4, Hits: 0},
+		{Line: 6, Hits: 1},
+	}, getLineHitCounts(blocks))
+}
+
+func TestGetBlocksCoverage(t *testing.T) {
+	blocks := []CodeBlockCoverage{
+		{CodeRange{"a", 1, 10, 3, 2}, 2, 4},
+		{CodeRange{"a", 3, 2, 4, 5}, 1, 0},
+		{CodeRange{"a", 6, 1, 6, 20}, 1, 1},
+	}
+	assert.Equal(t, SummaryReportCoverage{
+		TotalStatements:   4,
+		CoveredStatements: 3,
+		TotalLines:        5,
+		CoveredLines:      3,
+		TotalBlocks:       3,
+		CoveredBlocks:     2,
+	}, getBlocksCoverage(blocks, 1))
+	assert.Equal(t, SummaryReportCoverage{
+		TotalStatements:   4,
+		CoveredStatements: 2,
+		TotalLines:        5,
+		CoveredLines:      2,
+		TotalBlocks:       3,
+		CoveredBlocks:     1,
+	}, getBlocksCoverage(blocks, 2))
+}
+
+func TestGetPartialLines(t *testing.T) {
+	blocks := []CodeBlockCoverage{
+		{CodeRange{"a", 1, 10, 3, 2}, 2, 4},
+		{CodeRange{"a", 3, 2, 4, 5}, 1, 0},
+		{CodeRange{"a", 4, 5, 5, 1}, 1, 0},
+		{CodeRange{"a", 6, 1, 6, 8}, 1, 1},
+		{CodeRange{"a", 6, 8, 6, 20}, 1, 0},
+		{CodeRange{"a", 7, 1, 8, 1}, 1, 1},
+		{CodeRange{"a", 8, 1, 8, 10}, 1, 0},
+	}
+	assert.Equal(t, []int{3, 6}, getPartialLines(blocks, 1))
+	assert.Equal(t, []int{3}, getPartialLines(blocks, 2))
+	assert.Nil(t, getPartialLines(blocks[1:3], 1))
+}
@@ -3,21 +3,29 @@ package main
 import (
 	"fmt"
 	"os"
+	"time"
 )
 
 func main() {
+	if len(os.Args) > 1 {
+		switch os.Args[1] {
+		case perTestCommandName:
+			runPerTestCommand(os.Args[1:])
+			return
+		case trendCommandName:
+			runTrendCommand(os.Args[1:])
+			return
+		case diffCommandName:
+			runDiffCommand(os.Args[1:])
+			return
+		}
+	}
+
 	options, ok := ReadCommandLineOptions(os.Args, os.Stderr)
 	if !ok {
 		os.Exit(1)
 	}
-
-	if options.PackagePath == "" {
-		options.PackagePath = InferPackagePath()
-		if options.PackagePath == "" {
-			fmt.Fprintln(os.Stderr, "Unable to determine package path; use -package option")
-			os.Exit(1)
-		}
-	}
+	inferPackagePathIfNecessary(&options)
 
 	f, err := os.Open(options.InputFilePath)
 	if err != nil {
@@ -35,14 +43,34 @@ func main() {
 	exitIfError(err)
 
 	report := NewSummaryReport(result, options)
-	report.Output(os.Stdout, options)
+	onlyTextOnStdout := true
+	for _, r := range options.Reports {
+		if r.FilePath == "" {
+			stdoutOptions := options
+			stdoutOptions.UseColor = shouldUseColor(options.ColorMode, os.Stdout)
+			exitIfError(WriteReport(os.Stdout, r.Format, report, result, stdoutOptions))
+			onlyTextOnStdout = onlyTextOnStdout && r.Format == "text"
+		} else {
+			exitIfError(writeReportFile(r, report, result, options))
+		}
+	}
 
 	if options.OutputFilePath != "" {
 		f1, err := os.Create(options.OutputFilePath)
 		exitIfError(err)
 		defer f1.Close()
 		exitIfError(result.WriteFilteredProfile(profile, f1))
-		fmt.Println("Filtered profile written to", options.OutputFilePath)
+		if onlyTextOnStdout {
+			fmt.Println("Filtered profile written to", options.OutputFilePath)
+		} else {
+			// don't add anything to standard output that isn't part of the report
+			fmt.Fprintln(os.Stderr, "Filtered profile written to", options.OutputFilePath)
+		}
+	}
+
+	if options.HistoryFilePath != "" {
+		record := NewHistoryRecord(report, options, time.Now(), getGitCommitSHA())
+		exitIfError(AppendHistoryRecord(options.HistoryFilePath, record))
 	}
 
 	if !report.Pass {
@@ -50,6 +78,80 @@ func main() {
 	}
 }
 
+func runPerTestCommand(args []string) {
+	options, ok := ReadPerTestCommandLineOptions(args, os.Stderr)
+	if !ok {
+		os.Exit(1)
+	}
+	inferPackagePathIfNecessary(&options)
+
+	result, err := AnalyzePerTestCoverage(options.InputFilePath, options)
+	exitIfError(err)
+	result.Output(os.Stdout)
+}
+
+func runTrendCommand(args []string) {
+	options, ok := ReadTrendCommandLineOptions(args, os.Stderr)
+	if !ok {
+		os.Exit(1)
+	}
+
+	f, err := os.Open(options.InputFilePath)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "Unable to read", options.InputFilePath)
+		os.Exit(1)
+	}
+	defer f.Close()
+	records, err := ReadHistory(f)
+	exitIfError(err)
+	WriteTrend(os.Stdout, records, options)
+}
+
+func runDiffCommand(args []string) {
+	options, ok := ReadDiffCommandLineOptions(args, os.Stderr)
+	if !ok {
+		os.Exit(1)
+	}
+	inferPackagePathIfNecessary(&options)
+	options.UseColor = shouldUseColor(options.ColorMode, os.Stdout)
+
+	oldProfile, err := readCoverageProfileFile(options.OldInputFilePath)
+	exitIfError(err)
+	newProfile, err := readCoverageProfileFile(options.InputFilePath)
+	exitIfError(err)
+
+	diff, err := DiffCoverage(oldProfile, newProfile, options)
+	exitIfError(err)
+	diff.Output(os.Stdout, options)
+
+	if len(diff.NewlyUncoveredBlocks) != 0 {
+		os.Exit(1)
+	}
+}
+
+func inferPackagePathIfNecessary(options *EnforcerOptions) {
+	if options.PackagePath == "" {
+		options.PackagePath = InferPackagePath()
+		if options.PackagePath == "" {
+			fmt.Fprintln(os.Stderr, "Unable to determine package path; use -package option")
+			os.Exit(1)
+		}
+	}
+}
+
+func writeReportFile(spec ReportSpec, report SummaryReport, result AnalyzerResult, options EnforcerOptions) error {
+	f, err := os.Create(spec.FilePath)
+	if err != nil {
+		return err
+	}
+	options.UseColor = shouldUseColor(options.ColorMode, f)
+	if err := WriteReport(f, spec.Format, report, result, options); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
 func exitIfError(err error) {
 	if err != nil {
 		fmt.Fprintln(os.Stderr, "Error:", err)
@@ -0,0 +1,93 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"path"
+	"strings"
+)
+
+const (
+	markdownPassIcon = "✅"
+	markdownFailIcon = "❌"
+)
+
+// WriteMarkdownReport writes the report data as GitHub-flavored Markdown, suitable for a pull request
+// comment or a CI job summary. It contains the outcome of each rule, a table of package statistics,
+// and a collapsible section for each file that has uncovered blocks, listing the blocks and (if
+// "-showcode" was used) their source code.
+func WriteMarkdownReport(writer io.Writer, report SummaryReport, result AnalyzerResult, opts EnforcerOptions) error {
+	bw := bufio.NewWriter(writer)
+
+	fmt.Fprintf(bw, "## Coverage report: `%s`\n\n", opts.PackagePath)
+	if report.Pass {
+		fmt.Fprintf(bw, "%s **Coverage scan passes!**\n\n", markdownPassIcon)
+	} else {
+		fmt.Fprintf(bw, "%s **Coverage scan failed.**\n\n", markdownFailIcon)
+	}
+	for _, rule := range report.Rules {
+		fmt.Fprintf(bw, "- %s %s: %s\n", getMarkdownIcon(rule.Pass), rule.Name, rule.Message)
+	}
+
+	packageResults := getPackageResultsByPath(result, opts)
+
+	fmt.Fprintln(bw)
+	fmt.Fprintln(bw, "| Package | Statements | Coverage | |")
+	fmt.Fprintln(bw, "|:--|--:|--:|:-:|")
+	for _, rp := range report.Packages {
+		pass := true
+		for _, f := range packageResults[rp.FullPackagePath].Files {
+			pass = pass && len(f.UncoveredBlocks) == 0
+		}
+		writeMarkdownCoverageRow(bw, "`"+rp.FullPackagePath+"`", rp.Coverage, pass)
+	}
+	writeMarkdownCoverageRow(bw, "**Total**", report.GetTotalCoverage(), report.Pass)
+
+	for _, rp := range report.Packages {
+		p := packageResults[rp.FullPackagePath]
+		for i, f := range p.Files {
+			if len(f.UncoveredBlocks) == 0 {
+				continue
+			}
+			c := rp.Files[i].Coverage
+			fmt.Fprintf(bw, "\n<details>\n<summary><code>%s</code>: %d/%d statements (%d%%), %d uncovered block(s)</summary>\n\n",
+				path.Join(p.RelativePath, f.FileName),
+				c.CoveredStatements,
+				c.TotalStatements,
+				c.GetCoveredPercent(),
+				len(f.UncoveredBlocks),
+			)
+			for _, b := range f.UncoveredBlocks {
+				fmt.Fprintf(bw, "- lines %d-%d\n", b.CodeRange.StartLine, b.CodeRange.EndLine)
+				if len(b.Text) > 0 {
+					fmt.Fprintf(bw, "\n  ```go\n")
+					for _, line := range b.Text {
+						fmt.Fprintf(bw, "  %s\n", strings.TrimRight(line, " \t"))
+					}
+					fmt.Fprintf(bw, "  ```\n")
+				}
+			}
+			fmt.Fprintf(bw, "\n</details>\n")
+		}
+	}
+
+	return bw.Flush()
+}
+
+func writeMarkdownCoverageRow(writer io.Writer, name string, c SummaryReportCoverage, pass bool) {
+	fmt.Fprintf(writer, "| %s | %d/%d | %d%% | %s |\n",
+		name,
+		c.CoveredStatements,
+		c.TotalStatements,
+		c.GetCoveredPercent(),
+		getMarkdownIcon(pass),
+	)
+}
+
+func getMarkdownIcon(pass bool) string {
+	if pass {
+		return markdownPassIcon
+	}
+	return markdownFailIcon
+}
@@ -0,0 +1,69 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMarkdownReport(t *testing.T) {
+	t.Run("report from data with coverage gaps", func(t *testing.T) {
+		withValidTestProfile(testDataReportNotPassFile, func(cp *CoverageProfile) {
+			opts := testBaseOptions
+			opts.ShowCode = true
+			assert.Equal(t, "## Coverage report: `base-package`"+`
+
+❌ **Coverage scan failed.**
+
+- ❌ uncovered-blocks: 2 uncovered block(s) detected
+
+| Package | Statements | Coverage | |
+|:--|--:|--:|:-:|
+| `+"`base-package`"+` | 6/8 | 75% | ❌ |
+| `+"`base-package/otherpackage`"+` | 0/7 | 0% | ❌ |
+| **Total** | 6/15 | 40% | ❌ |
+
+<details>
+<summary><code>first</code>: 2/4 statements (50%), 1 uncovered block(s)</summary>
+
+- lines 3-4
+
+  `+"```go"+`
+  first file line 3
+  first file line 4
+  `+"```"+`
+
+</details>
+
+<details>
+<summary><code>otherpackage/first</code>: 0/7 statements (0%), 1 uncovered block(s)</summary>
+
+- lines 1-3
+
+  `+"```go"+`
+  other package first file line 1
+  other package first file line 2
+  other package first file line 3
+  `+"```"+`
+
+</details>
+`, writeReportForTest(t, cp, opts, "markdown"))
+		})
+	})
+
+	t.Run("report from data with no coverage gaps", func(t *testing.T) {
+		withValidTestProfile(testDataReportPassFile, func(cp *CoverageProfile) {
+			assert.Equal(t, "## Coverage report: `base-package`"+`
+
+✅ **Coverage scan passes!**
+
+- ✅ uncovered-blocks: no uncovered blocks
+
+| Package | Statements | Coverage | |
+|:--|--:|--:|:-:|
+| `+"`base-package`"+` | 6/6 | 100% | ✅ |
+| **Total** | 6/6 | 100% | ✅ |
+`, writeReportForTest(t, cp, testBaseOptions, "markdown"))
+		})
+	})
+}
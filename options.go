@@ -4,21 +4,114 @@ import (
 	"flag"
 	"fmt"
 	"io"
+	"path/filepath"
 	"regexp"
+	"strings"
 )
 
-const usageMessage = "go-coverage-enforcer [options] <coverage file>"
+const (
+	usageMessage        = "go-coverage-enforcer [options] <coverage file>"
+	perTestUsageMessage = "go-coverage-enforcer pertest [options] <directory of coverage files>"
+	trendUsageMessage   = "go-coverage-enforcer trend [options] <history file>"
+	diffUsageMessage    = "go-coverage-enforcer diff [options] <old coverage file> <new coverage file>"
+)
+
+// These are the first command-line arguments that select a command other than the default report.
+const (
+	perTestCommandName = "pertest"
+	trendCommandName   = "trend"
+	diffCommandName    = "diff"
+)
 
 // EnforcerOptions is a representation of the command-line options passed to the program.
 type EnforcerOptions struct {
-	InputFilePath    string
-	PackagePath      string
-	SkipFilesPattern *regexp.Regexp
-	SkipCodePattern  *regexp.Regexp
-	ShowPackageStats bool
-	ShowFileStats    bool
-	ShowCode         bool
-	OutputFilePath   string
+	InputFilePath     string
+	OldInputFilePath  string
+	OldSourceDir      string
+	PackagePath       string
+	SkipFilesPattern  *regexp.Regexp
+	SkipCodePattern   *regexp.Regexp
+	ShowPackageStats  bool
+	ShowFileStats     bool
+	ShowPackageTree   bool
+	ShowFunctionStats bool
+	PackageTreeDepth  int
+	ShowCode          bool
+	ContextLines      int
+	ShowSkipped       bool
+	MergeBlocks       bool
+	ShowPartialLines  bool
+	MinHits           int
+	ColdBlockHits     int
+	HotBlockCount     int
+	CheckPartialLines bool
+	MaxPartialLines   int
+	StaleMode         string
+	ColorMode         string
+	SortOrder         string
+	Metric            string
+	TopCount          int
+	MaxBlocks         int
+	OutputFilePath    string
+	OutputFormat      string
+	TemplateFilePath  string
+	HistoryFilePath   string
+	TrendRuns         int
+	Reports           []ReportSpec
+
+	// SourceDir is the directory that the source files' relative paths are based on, if it is not
+	// the current directory. It is only set for the old profile in the "diff" command.
+	SourceDir string
+
+	// SkipSourcePostPass is true if AnalyzeCoverage should not read the source files again after
+	// filtering to check for stale ranges, find the function containing each block, or merge blocks.
+	// It is not set by ReadCommandLineOptions, but is used by the "pertest" command, which analyzes
+	// many profiles and does not show any of that information.
+	SkipSourcePostPass bool
+
+	// UseColor is true if ANSI color sequences should be used in the text output. It is not set by
+	// ReadCommandLineOptions, but is computed from ColorMode for each output destination.
+	UseColor bool
+}
+
+// ReportSpec describes one of the reports that will be written, as specified by the "-format" or
+// "-report" option.
+type ReportSpec struct {
+	// Format is the name of the output format, such as "text" or "json".
+	Format string
+
+	// FilePath is the path of the file to write the report to, or "" for standard output.
+	FilePath string
+}
+
+// reportSpecsValue implements flag.Value for the "-report" option, which can be repeated.
+type reportSpecsValue []ReportSpec
+
+func (v *reportSpecsValue) String() string {
+	if v == nil {
+		return ""
+	}
+	var parts []string
+	for _, r := range *v {
+		if r.FilePath == "" {
+			parts = append(parts, r.Format)
+		} else {
+			parts = append(parts, r.Format+":"+r.FilePath)
+		}
+	}
+	return strings.Join(parts, " ")
+}
+
+func (v *reportSpecsValue) Set(s string) error {
+	r := ReportSpec{Format: s}
+	if i := strings.Index(s, ":"); i >= 0 {
+		r.Format, r.FilePath = s[:i], s[i+1:]
+	}
+	if _, ok := reportFormats[r.Format]; !ok {
+		return fmt.Errorf("not a valid output format: %s (must be one of: %s)", r.Format, getReportFormatNames())
+	}
+	*v = append(*v, r)
+	return nil
 }
 
 // ReadCommandLineOptions parses the options from the command line. If they were invalid, it
@@ -28,16 +121,38 @@ func ReadCommandLineOptions(argsIn []string, errWriter io.Writer) (EnforcerOptio
 
 	var skipFilesPattern string
 	var skipCodePattern string
+	var reports reportSpecsValue
 
 	flags := flag.NewFlagSet(usageMessage, flag.ContinueOnError)
 	flags.SetOutput(errWriter)
 	flags.StringVar(&opts.PackagePath, "package", "", "base import path of this package")
 	flags.BoolVar(&opts.ShowPackageStats, "packagestats", false, "show package-level statistics after filtering")
 	flags.BoolVar(&opts.ShowFileStats, "filestats", false, "show file-level statistics after filtering")
+	flags.BoolVar(&opts.ShowPackageTree, "packagetree", false, "show statistics for each directory, including subdirectories")
+	flags.BoolVar(&opts.ShowFunctionStats, "funcstats", false, "show function-level statistics after filtering")
+	flags.IntVar(&opts.PackageTreeDepth, "treedepth", 0, "with -packagetree, the maximum directory depth to show (0 = unlimited)")
 	flags.BoolVar(&opts.ShowCode, "showcode", false, "display source code of uncovered blocks")
+	flags.IntVar(&opts.ContextLines, "context", 0, "show N lines of source code before and after each uncovered block (implies -showcode)")
+	flags.BoolVar(&opts.MergeBlocks, "mergeblocks", false, "merge uncovered blocks that are separated only by whitespace and comments")
+	flags.StringVar(&opts.ColorMode, "color", colorModeAuto, "use color in text output ("+getColorModeNames()+")")
+	flags.BoolVar(&opts.ShowPartialLines, "partiallines", false, "list lines that are only partly covered")
+	flags.IntVar(&opts.MaxPartialLines, "maxpartial", -1, "fail if there are more than N partly covered lines (-1 = no limit)")
+	flags.IntVar(&opts.MinHits, "minhits", 1, "treat blocks executed fewer than N times as uncovered (requires count or atomic mode)")
+	flags.IntVar(&opts.ColdBlockHits, "showcold", 0, "list covered blocks that were executed at most N times (requires count or atomic mode)")
+	flags.IntVar(&opts.HotBlockCount, "showhot", 0, "list the N most frequently executed blocks (requires count or atomic mode)")
+	flags.StringVar(&opts.StaleMode, "stale", staleModeWarn, "what to do if the profile does not match the source code ("+getStaleModeNames()+")")
+	flags.BoolVar(&opts.ShowSkipped, "showskipped", false, "list the files and blocks that were skipped, and why")
+	flags.StringVar(&opts.SortOrder, "sort", sortByPath, "order of stats and uncovered blocks ("+getSortOrderNames()+")")
+	flags.StringVar(&opts.Metric, "metric", metricStatements, "coverage metric shown in stats ("+getCoverageMetricNames()+")")
+	flags.IntVar(&opts.TopCount, "top", 0, "show only the N packages or files with the worst coverage in stats (0 = all)")
+	flags.IntVar(&opts.MaxBlocks, "maxblocks", 0, "show at most N uncovered blocks (0 = all)")
 	flags.StringVar(&skipFilesPattern, "skipfiles", "", "regex pattern for file paths to be ignored")
 	flags.StringVar(&skipCodePattern, "skipcode", "", "regex pattern for ignoring a code block")
 	flags.StringVar(&opts.OutputFilePath, "outprofile", "", "save the filtered coverage profile to this path")
+	flags.StringVar(&opts.OutputFormat, "format", "text", "output format ("+getReportFormatNames()+")")
+	flags.StringVar(&opts.TemplateFilePath, "template", "", "render the report with this Go text/template file")
+	flags.StringVar(&opts.HistoryFilePath, "history", "", "append a record of this run to a JSON Lines history file")
+	flags.Var(&reports, "report", "write a report in the format FORMAT[:PATH] (can be repeated; default path is stdout)")
 	err := flags.Parse(argsIn[1:])
 
 	if err != nil {
@@ -59,10 +174,255 @@ func ReadCommandLineOptions(argsIn []string, errWriter io.Writer) (EnforcerOptio
 	if opts.SkipCodePattern, ok = maybeRegexpParam(skipCodePattern, errWriter); !ok {
 		return opts, false
 	}
+	if opts.ContextLines < 0 {
+		fmt.Fprintf(errWriter, "Not a valid number of context lines: %d\n", opts.ContextLines)
+		return opts, false
+	}
+	if opts.ContextLines > 0 {
+		opts.ShowCode = true
+	}
+	if opts.PackageTreeDepth < 0 {
+		fmt.Fprintf(errWriter, "Not a valid tree depth: %d\n", opts.PackageTreeDepth)
+		return opts, false
+	}
+	opts.CheckPartialLines = opts.MaxPartialLines >= 0
+	if opts.MinHits < 1 {
+		fmt.Fprintf(errWriter, "Not a valid number of hits for -minhits: %d\n", opts.MinHits)
+		return opts, false
+	}
+	if opts.ColdBlockHits < 0 {
+		fmt.Fprintf(errWriter, "Not a valid number of hits for -showcold: %d\n", opts.ColdBlockHits)
+		return opts, false
+	}
+	if opts.HotBlockCount < 0 {
+		fmt.Fprintf(errWriter, "Not a valid number of blocks for -showhot: %d\n", opts.HotBlockCount)
+		return opts, false
+	}
+	if !isValidColorMode(opts.ColorMode) {
+		fmt.Fprintf(errWriter, "Not a valid color mode: %s (must be one of: %s)\n",
+			opts.ColorMode, getColorModeNames())
+		return opts, false
+	}
+	if !isValidCoverageMetric(opts.Metric) {
+		fmt.Fprintf(errWriter, "Not a valid coverage metric: %s (must be one of: %s)\n",
+			opts.Metric, getCoverageMetricNames())
+		return opts, false
+	}
+	if !isValidStaleMode(opts.StaleMode) {
+		fmt.Fprintf(errWriter, "Not a valid stale profile mode: %s (must be one of: %s)\n",
+			opts.StaleMode, getStaleModeNames())
+		return opts, false
+	}
+	if opts.TopCount < 0 {
+		fmt.Fprintf(errWriter, "Not a valid number of packages or files for -top: %d\n", opts.TopCount)
+		return opts, false
+	}
+	if opts.MaxBlocks < 0 {
+		fmt.Fprintf(errWriter, "Not a valid number of blocks for -maxblocks: %d\n", opts.MaxBlocks)
+		return opts, false
+	}
+	if !isValidSortOrder(opts.SortOrder) {
+		fmt.Fprintf(errWriter, "Not a valid sort order: %s (must be one of: %s)\n",
+			opts.SortOrder, getSortOrderNames())
+		return opts, false
+	}
+	if opts.TemplateFilePath != "" && !isFlagSet(flags, "format") && len(reports) == 0 {
+		opts.OutputFormat = "template"
+	}
+	if _, ok = reportFormats[opts.OutputFormat]; !ok {
+		fmt.Fprintf(errWriter, "Not a valid output format: %s (must be one of: %s)\n",
+			opts.OutputFormat, getReportFormatNames())
+		return opts, false
+	}
+
+	// The report selected by -format goes to standard output if it was explicitly specified, or if
+	// none of the -report options specified standard output.
+	hasStdoutReport := false
+	for _, r := range reports {
+		hasStdoutReport = hasStdoutReport || r.FilePath == ""
+	}
+	if isFlagSet(flags, "format") || !hasStdoutReport {
+		opts.Reports = append(opts.Reports, ReportSpec{Format: opts.OutputFormat})
+	}
+	opts.Reports = append(opts.Reports, reports...)
+	reportFilePaths := make(map[string]bool)
+	for _, r := range opts.Reports {
+		filePath := r.FilePath
+		if filePath != "" {
+			filePath = filepath.Clean(filePath)
+		}
+		if reportFilePaths[filePath] {
+			if filePath == "" {
+				fmt.Fprintln(errWriter, "Only one report can be written to standard output; use -report FORMAT:FILEPATH for the others")
+			} else {
+				fmt.Fprintf(errWriter, "More than one report would be written to the same file: %s\n", r.FilePath)
+			}
+			return opts, false
+		}
+		reportFilePaths[filePath] = true
+	}
+	hasTemplateReport := false
+	for _, r := range opts.Reports {
+		hasTemplateReport = hasTemplateReport || r.Format == "template"
+	}
+	if hasTemplateReport && opts.TemplateFilePath == "" {
+		fmt.Fprintln(errWriter, "The template format requires the -template option")
+		return opts, false
+	}
+	if !hasTemplateReport && opts.TemplateFilePath != "" {
+		fmt.Fprintln(errWriter, "The -template option requires the template format in -format or -report")
+		return opts, false
+	}
 
 	return opts, true
 }
 
+// ReadPerTestCommandLineOptions parses command-line arguments for the "pertest" command. The first
+// argument is the command name. Only the options that control filtering are allowed. If
+// unsuccessful, it prints a usage message and returns false.
+func ReadPerTestCommandLineOptions(argsIn []string, errWriter io.Writer) (EnforcerOptions, bool) {
+	var opts EnforcerOptions
+
+	var skipFilesPattern string
+	var skipCodePattern string
+
+	flags := flag.NewFlagSet(perTestUsageMessage, flag.ContinueOnError)
+	flags.SetOutput(errWriter)
+	flags.StringVar(&opts.PackagePath, "package", "", "base import path of this package")
+	flags.IntVar(&opts.MinHits, "minhits", 1, "treat blocks executed fewer than N times as uncovered (requires count or atomic mode)")
+	flags.StringVar(&skipFilesPattern, "skipfiles", "", "regex pattern for file paths to be ignored")
+	flags.StringVar(&skipCodePattern, "skipcode", "", "regex pattern for ignoring a code block")
+	err := flags.Parse(argsIn[1:])
+
+	if err != nil {
+		return opts, false
+	}
+
+	leftoverArgs := flags.Args()
+	if len(leftoverArgs) != 1 {
+		fmt.Fprintln(errWriter, perTestUsageMessage)
+		flags.PrintDefaults()
+		return opts, false
+	}
+	opts.InputFilePath = leftoverArgs[0]
+
+	var ok bool
+	if opts.SkipFilesPattern, ok = maybeRegexpParam(skipFilesPattern, errWriter); !ok {
+		return opts, false
+	}
+	if opts.SkipCodePattern, ok = maybeRegexpParam(skipCodePattern, errWriter); !ok {
+		return opts, false
+	}
+	if opts.MinHits < 1 {
+		fmt.Fprintf(errWriter, "Not a valid number of hits for -minhits: %d\n", opts.MinHits)
+		return opts, false
+	}
+
+	return opts, true
+}
+
+// ReadTrendCommandLineOptions parses command-line arguments for the "trend" command. The first
+// argument is the command name. If unsuccessful, it prints a usage message and returns false.
+func ReadTrendCommandLineOptions(argsIn []string, errWriter io.Writer) (EnforcerOptions, bool) {
+	var opts EnforcerOptions
+
+	flags := flag.NewFlagSet(trendUsageMessage, flag.ContinueOnError)
+	flags.SetOutput(errWriter)
+	flags.IntVar(&opts.TrendRuns, "runs", 10, "show the last N runs (0 = all)")
+	flags.StringVar(&opts.Metric, "metric", metricStatements, "coverage metric to show ("+getCoverageMetricNames()+")")
+	err := flags.Parse(argsIn[1:])
+
+	if err != nil {
+		return opts, false
+	}
+
+	leftoverArgs := flags.Args()
+	if len(leftoverArgs) != 1 {
+		fmt.Fprintln(errWriter, trendUsageMessage)
+		flags.PrintDefaults()
+		return opts, false
+	}
+	opts.InputFilePath = leftoverArgs[0]
+
+	if opts.TrendRuns < 0 {
+		fmt.Fprintf(errWriter, "Not a valid number of runs: %d\n", opts.TrendRuns)
+		return opts, false
+	}
+	if !isValidCoverageMetric(opts.Metric) {
+		fmt.Fprintf(errWriter, "Not a valid coverage metric: %s (must be one of: %s)\n",
+			opts.Metric, getCoverageMetricNames())
+		return opts, false
+	}
+
+	return opts, true
+}
+
+// ReadDiffCommandLineOptions parses command-line arguments for the "diff" command. The first
+// argument is the command name. If unsuccessful, it prints a usage message and returns false.
+func ReadDiffCommandLineOptions(argsIn []string, errWriter io.Writer) (EnforcerOptions, bool) {
+	var opts EnforcerOptions
+
+	var skipFilesPattern string
+	var skipCodePattern string
+
+	flags := flag.NewFlagSet(diffUsageMessage, flag.ContinueOnError)
+	flags.SetOutput(errWriter)
+	flags.StringVar(&opts.PackagePath, "package", "", "base import path of this package")
+	flags.StringVar(&opts.OldSourceDir, "olddir", "", "directory containing the source code for the old coverage file (default: current directory)")
+	flags.IntVar(&opts.MinHits, "minhits", 1, "treat blocks executed fewer than N times as uncovered (requires count or atomic mode)")
+	flags.StringVar(&opts.Metric, "metric", metricStatements, "coverage metric to show ("+getCoverageMetricNames()+")")
+	flags.StringVar(&opts.ColorMode, "color", colorModeAuto, "use color in output ("+getColorModeNames()+")")
+	flags.StringVar(&skipFilesPattern, "skipfiles", "", "regex pattern for file paths to be ignored")
+	flags.StringVar(&skipCodePattern, "skipcode", "", "regex pattern for ignoring a code block")
+	err := flags.Parse(argsIn[1:])
+
+	if err != nil {
+		return opts, false
+	}
+
+	leftoverArgs := flags.Args()
+	if len(leftoverArgs) != 2 {
+		fmt.Fprintln(errWriter, diffUsageMessage)
+		flags.PrintDefaults()
+		return opts, false
+	}
+	opts.OldInputFilePath, opts.InputFilePath = leftoverArgs[0], leftoverArgs[1]
+
+	var ok bool
+	if opts.SkipFilesPattern, ok = maybeRegexpParam(skipFilesPattern, errWriter); !ok {
+		return opts, false
+	}
+	if opts.SkipCodePattern, ok = maybeRegexpParam(skipCodePattern, errWriter); !ok {
+		return opts, false
+	}
+	if opts.MinHits < 1 {
+		fmt.Fprintf(errWriter, "Not a valid number of hits for -minhits: %d\n", opts.MinHits)
+		return opts, false
+	}
+	if !isValidCoverageMetric(opts.Metric) {
+		fmt.Fprintf(errWriter, "Not a valid coverage metric: %s (must be one of: %s)\n",
+			opts.Metric, getCoverageMetricNames())
+		return opts, false
+	}
+	if !isValidColorMode(opts.ColorMode) {
+		fmt.Fprintf(errWriter, "Not a valid color mode: %s (must be one of: %s)\n",
+			opts.ColorMode, getColorModeNames())
+		return opts, false
+	}
+
+	return opts, true
+}
+
+func isFlagSet(flags *flag.FlagSet, name string) bool {
+	found := false
+	flags.Visit(func(f *flag.Flag) {
+		if f.Name == name {
+			found = true
+		}
+	})
+	return found
+}
+
 func maybeRegexpParam(s string, errWriter io.Writer) (*regexp.Regexp, bool) {
 	if s == "" {
 		return nil, true
@@ -51,12 +51,112 @@ func TestReadCommandLineOptions(t *testing.T) {
 			assert.Nil(t, opts.SkipCodePattern)
 			assert.False(t, opts.ShowCode)
 			assert.Equal(t, "", opts.OutputFilePath)
+			assert.Equal(t, "text", opts.OutputFormat)
+			assert.Equal(t, []ReportSpec{{Format: "text"}}, opts.Reports)
+		})
+	})
+
+	t.Run("-report", func(t *testing.T) {
+		forValidCommandLine(t, "enforcer -report json:cov.json -report junit:out/junit.xml param1", func(opts EnforcerOptions) {
+			assert.Equal(t, []ReportSpec{
+				{Format: "text"},
+				{Format: "json", FilePath: "cov.json"},
+				{Format: "junit", FilePath: "out/junit.xml"},
+			}, opts.Reports)
+		})
+
+		forValidCommandLine(t, "enforcer -report github -report json:cov.json param1", func(opts EnforcerOptions) {
+			assert.Equal(t, []ReportSpec{
+				{Format: "github"},
+				{Format: "json", FilePath: "cov.json"},
+			}, opts.Reports)
+		})
+
+		forValidCommandLine(t, "enforcer -format markdown -report text:out.txt param1", func(opts EnforcerOptions) {
+			assert.Equal(t, []ReportSpec{{Format: "markdown"}, {Format: "text", FilePath: "out.txt"}}, opts.Reports)
+		})
+
+		forInvalidCommandLine(t, "enforcer -format markdown -report text param1", func(errorOutput string) {
+			assert.Contains(t, errorOutput, "Only one report can be written to standard output")
+		})
+
+		forInvalidCommandLine(t, "enforcer -report text -report json param1", func(errorOutput string) {
+			assert.Contains(t, errorOutput, "Only one report can be written to standard output")
+		})
+
+		forInvalidCommandLine(t, "enforcer -report json:out/cov.json -report junit:out/../out/cov.json param1", func(errorOutput string) {
+			assert.Contains(t, errorOutput, "More than one report would be written to the same file: out/../out/cov.json")
+		})
+
+		forValidCommandLine(t, "enforcer -template t.tmpl -report template:out.txt param1", func(opts EnforcerOptions) {
+			assert.Equal(t, []ReportSpec{{Format: "text"}, {Format: "template", FilePath: "out.txt"}}, opts.Reports)
+		})
+
+		forInvalidCommandLine(t, "enforcer -report xyz:out.txt param1", func(errorOutput string) {
+			assert.Contains(t, errorOutput, "not a valid output format")
+		})
+
+		forInvalidCommandLine(t, "enforcer -report template:out.txt param1", func(errorOutput string) {
+			assert.Contains(t, errorOutput, "requires the -template option")
+		})
+	})
+
+	t.Run("-format", func(t *testing.T) {
+		forValidCommandLine(t, "enforcer -format json param1", func(opts EnforcerOptions) {
+			assert.Equal(t, "json", opts.OutputFormat)
+			assert.Equal(t, []ReportSpec{{Format: "json"}}, opts.Reports)
+		})
+
+		forInvalidCommandLine(t, "enforcer -format xyz param1", func(errorOutput string) {
+			assert.Contains(t, errorOutput, "Not a valid output format")
 		})
 	})
 
 	t.Run("-filestats", validateBool("filestats",
 		func(opts EnforcerOptions) bool { return opts.ShowFileStats }))
 
+	t.Run("-template", func(t *testing.T) {
+		forValidCommandLine(t, "enforcer -template report.tmpl param1", func(opts EnforcerOptions) {
+			assert.Equal(t, "report.tmpl", opts.TemplateFilePath)
+			assert.Equal(t, "template", opts.OutputFormat)
+		})
+
+		forValidCommandLine(t, "enforcer -template report.tmpl -format json -report template:out.txt param1", func(opts EnforcerOptions) {
+			assert.Equal(t, "report.tmpl", opts.TemplateFilePath)
+			assert.Equal(t, []ReportSpec{{Format: "json"}, {Format: "template", FilePath: "out.txt"}}, opts.Reports)
+		})
+
+		forInvalidCommandLine(t, "enforcer -template report.tmpl -format json param1", func(errorOutput string) {
+			assert.Contains(t, errorOutput, "requires the template format")
+		})
+
+		forInvalidCommandLine(t, "enforcer -template report.tmpl -report json:cov.json param1", func(errorOutput string) {
+			assert.Contains(t, errorOutput, "requires the template format")
+		})
+
+		forInvalidCommandLine(t, "enforcer -format template param1", func(errorOutput string) {
+			assert.Contains(t, errorOutput, "requires the -template option")
+		})
+	})
+
+	t.Run("-stale", func(t *testing.T) {
+		forValidCommandLine(t, "enforcer param1", func(opts EnforcerOptions) {
+			assert.Equal(t, staleModeWarn, opts.StaleMode)
+		})
+		forValidCommandLine(t, "enforcer -stale fail param1", func(opts EnforcerOptions) {
+			assert.Equal(t, staleModeFail, opts.StaleMode)
+		})
+		forInvalidCommandLine(t, "enforcer -stale x param1", func(errorOutput string) {
+			assert.Contains(t, errorOutput, "Not a valid stale profile mode: x")
+		})
+	})
+
+	t.Run("-history", func(t *testing.T) {
+		forValidCommandLine(t, "enforcer -history history.jsonl param1", func(opts EnforcerOptions) {
+			assert.Equal(t, "history.jsonl", opts.HistoryFilePath)
+		})
+	})
+
 	t.Run("-outprofile", func(t *testing.T) {
 		forValidCommandLine(t, "enforcer -outprofile newfile param1", func(opts EnforcerOptions) {
 			assert.Equal(t, "newfile", opts.OutputFilePath)
@@ -69,12 +169,160 @@ func TestReadCommandLineOptions(t *testing.T) {
 		})
 	})
 
+	t.Run("-funcstats", validateBool("funcstats",
+		func(opts EnforcerOptions) bool { return opts.ShowFunctionStats }))
+
+	t.Run("-packagetree", validateBool("packagetree",
+		func(opts EnforcerOptions) bool { return opts.ShowPackageTree }))
+
+	t.Run("-treedepth", func(t *testing.T) {
+		forValidCommandLine(t, "enforcer -treedepth 2 param1", func(opts EnforcerOptions) {
+			assert.Equal(t, 2, opts.PackageTreeDepth)
+		})
+
+		forInvalidCommandLine(t, "enforcer -treedepth -1 param1", func(errorOutput string) {
+			assert.Contains(t, errorOutput, "Not a valid tree depth: -1")
+		})
+	})
+
 	t.Run("-packagestats", validateBool("packagestats",
 		func(opts EnforcerOptions) bool { return opts.ShowPackageStats }))
 
 	t.Run("-showcode", validateBool("showcode",
 		func(opts EnforcerOptions) bool { return opts.ShowCode }))
 
+	t.Run("-context", func(t *testing.T) {
+		forValidCommandLine(t, "enforcer -context 2 param1", func(opts EnforcerOptions) {
+			assert.Equal(t, 2, opts.ContextLines)
+			assert.True(t, opts.ShowCode)
+		})
+
+		forInvalidCommandLine(t, "enforcer -context -1 param1", func(errorOutput string) {
+			assert.Contains(t, errorOutput, "Not a valid number of context lines: -1")
+		})
+	})
+
+	t.Run("-mergeblocks", validateBool("mergeblocks",
+		func(opts EnforcerOptions) bool { return opts.MergeBlocks }))
+
+	t.Run("-color", func(t *testing.T) {
+		forValidCommandLine(t, "enforcer param1", func(opts EnforcerOptions) {
+			assert.Equal(t, colorModeAuto, opts.ColorMode)
+			assert.False(t, opts.UseColor)
+		})
+
+		forValidCommandLine(t, "enforcer -color never param1", func(opts EnforcerOptions) {
+			assert.Equal(t, colorModeNever, opts.ColorMode)
+		})
+
+		forInvalidCommandLine(t, "enforcer -color sometimes param1", func(errorOutput string) {
+			assert.Contains(t, errorOutput, "Not a valid color mode: sometimes")
+		})
+	})
+
+	t.Run("-partiallines", validateBool("partiallines",
+		func(opts EnforcerOptions) bool { return opts.ShowPartialLines }))
+
+	t.Run("-maxpartial", func(t *testing.T) {
+		forValidCommandLine(t, "enforcer param1", func(opts EnforcerOptions) {
+			assert.False(t, opts.CheckPartialLines)
+		})
+
+		forValidCommandLine(t, "enforcer -maxpartial 0 param1", func(opts EnforcerOptions) {
+			assert.True(t, opts.CheckPartialLines)
+			assert.Equal(t, 0, opts.MaxPartialLines)
+		})
+	})
+
+	t.Run("-minhits", func(t *testing.T) {
+		forValidCommandLine(t, "enforcer param1", func(opts EnforcerOptions) {
+			assert.Equal(t, 1, opts.MinHits)
+		})
+
+		forValidCommandLine(t, "enforcer -minhits 5 param1", func(opts EnforcerOptions) {
+			assert.Equal(t, 5, opts.MinHits)
+		})
+
+		forInvalidCommandLine(t, "enforcer -minhits 0 param1", func(errorOutput string) {
+			assert.Contains(t, errorOutput, "Not a valid number of hits for -minhits: 0")
+		})
+
+		forInvalidCommandLine(t, "enforcer -minhits -5 param1", func(errorOutput string) {
+			assert.Contains(t, errorOutput, "Not a valid number of hits for -minhits: -5")
+		})
+	})
+
+	t.Run("-showcold", func(t *testing.T) {
+		forValidCommandLine(t, "enforcer -showcold 2 param1", func(opts EnforcerOptions) {
+			assert.Equal(t, 2, opts.ColdBlockHits)
+		})
+
+		forInvalidCommandLine(t, "enforcer -showcold -1 param1", func(errorOutput string) {
+			assert.Contains(t, errorOutput, "Not a valid number of hits for -showcold: -1")
+		})
+	})
+
+	t.Run("-showhot", func(t *testing.T) {
+		forValidCommandLine(t, "enforcer -showhot 10 param1", func(opts EnforcerOptions) {
+			assert.Equal(t, 10, opts.HotBlockCount)
+		})
+
+		forInvalidCommandLine(t, "enforcer -showhot -1 param1", func(errorOutput string) {
+			assert.Contains(t, errorOutput, "Not a valid number of blocks for -showhot: -1")
+		})
+	})
+
+	t.Run("-showskipped", validateBool("showskipped",
+		func(opts EnforcerOptions) bool { return opts.ShowSkipped }))
+
+	t.Run("-sort", func(t *testing.T) {
+		forValidCommandLine(t, "enforcer param1", func(opts EnforcerOptions) {
+			assert.Equal(t, sortByPath, opts.SortOrder)
+		})
+
+		forValidCommandLine(t, "enforcer -sort percent param1", func(opts EnforcerOptions) {
+			assert.Equal(t, sortByPercent, opts.SortOrder)
+		})
+
+		forInvalidCommandLine(t, "enforcer -sort bogus param1", func(errorOutput string) {
+			assert.Contains(t, errorOutput, "Not a valid sort order: bogus")
+		})
+	})
+
+	t.Run("-metric", func(t *testing.T) {
+		forValidCommandLine(t, "enforcer param1", func(opts EnforcerOptions) {
+			assert.Equal(t, metricStatements, opts.Metric)
+		})
+
+		forValidCommandLine(t, "enforcer -metric lines param1", func(opts EnforcerOptions) {
+			assert.Equal(t, metricLines, opts.Metric)
+		})
+
+		forInvalidCommandLine(t, "enforcer -metric branches param1", func(errorOutput string) {
+			assert.Contains(t, errorOutput, "Not a valid coverage metric: branches")
+		})
+	})
+
+	t.Run("-top", func(t *testing.T) {
+		forValidCommandLine(t, "enforcer -top 5 param1", func(opts EnforcerOptions) {
+			assert.Equal(t, 5, opts.TopCount)
+		})
+
+		forInvalidCommandLine(t, "enforcer -top -1 param1", func(errorOutput string) {
+			assert.Contains(t, errorOutput, "Not a valid number of packages or files for -top: -1")
+		})
+	})
+
+	t.Run("-maxblocks", func(t *testing.T) {
+		forValidCommandLine(t, "enforcer -maxblocks 10 param1", func(opts EnforcerOptions) {
+			assert.Equal(t, 10, opts.MaxBlocks)
+		})
+
+		forInvalidCommandLine(t, "enforcer -maxblocks -1 param1", func(errorOutput string) {
+			assert.Contains(t, errorOutput, "Not a valid number of blocks for -maxblocks: -1")
+		})
+	})
+
 	t.Run("-skipfiles", func(t *testing.T) {
 		forValidCommandLine(t, "enforcer -skipfiles skip.*go param1", func(opts EnforcerOptions) {
 			assert.Equal(t, regexp.MustCompile("skip.*go"), opts.SkipFilesPattern)
@@ -114,3 +362,105 @@ func TestReadCommandLineOptions(t *testing.T) {
 		})
 	})
 }
+
+func TestReportSpecsValueString(t *testing.T) {
+	var nilValue *reportSpecsValue
+	assert.Equal(t, "", nilValue.String())
+
+	v := reportSpecsValue{{Format: "text"}, {Format: "json", FilePath: "cov.json"}}
+	assert.Equal(t, "text json:cov.json", v.String())
+}
+
+func TestReadPerTestCommandLineOptions(t *testing.T) {
+	t.Run("valid", func(t *testing.T) {
+		buf := new(bytes.Buffer)
+		opts, ok := ReadPerTestCommandLineOptions(strings.Split("pertest -package a/b -skipfiles x -minhits 2 dir", " "), buf)
+		if assert.True(t, ok) && assert.Equal(t, "", buf.String()) {
+			assert.Equal(t, "dir", opts.InputFilePath)
+			assert.Equal(t, "a/b", opts.PackagePath)
+			assert.Equal(t, "x", opts.SkipFilesPattern.String())
+			assert.Equal(t, 2, opts.MinHits)
+		}
+	})
+
+	t.Run("directory is required", func(t *testing.T) {
+		buf := new(bytes.Buffer)
+		_, ok := ReadPerTestCommandLineOptions([]string{"pertest"}, buf)
+		assert.False(t, ok)
+		assert.Contains(t, buf.String(), perTestUsageMessage)
+	})
+
+	t.Run("report options are not allowed", func(t *testing.T) {
+		buf := new(bytes.Buffer)
+		_, ok := ReadPerTestCommandLineOptions(strings.Split("pertest -format json dir", " "), buf)
+		assert.False(t, ok)
+		assert.Contains(t, buf.String(), "flag provided but not defined")
+	})
+
+	t.Run("invalid options", func(t *testing.T) {
+		for _, args := range []string{"pertest -skipfiles ( dir", "pertest -skipcode ( dir", "pertest -minhits 0 dir",
+			"pertest -minhits -5 dir"} {
+			buf := new(bytes.Buffer)
+			_, ok := ReadPerTestCommandLineOptions(strings.Split(args, " "), buf)
+			assert.False(t, ok, args)
+			assert.NotEqual(t, "", buf.String(), args)
+		}
+	})
+}
+
+func TestReadTrendCommandLineOptions(t *testing.T) {
+	t.Run("defaults", func(t *testing.T) {
+		buf := new(bytes.Buffer)
+		opts, ok := ReadTrendCommandLineOptions([]string{"trend", "history.jsonl"}, buf)
+		if assert.True(t, ok) && assert.Equal(t, "", buf.String()) {
+			assert.Equal(t, "history.jsonl", opts.InputFilePath)
+			assert.Equal(t, 10, opts.TrendRuns)
+			assert.Equal(t, metricStatements, opts.Metric)
+		}
+	})
+
+	t.Run("valid options", func(t *testing.T) {
+		buf := new(bytes.Buffer)
+		opts, ok := ReadTrendCommandLineOptions(strings.Split("trend -runs 5 -metric lines history.jsonl", " "), buf)
+		if assert.True(t, ok) && assert.Equal(t, "", buf.String()) {
+			assert.Equal(t, 5, opts.TrendRuns)
+			assert.Equal(t, metricLines, opts.Metric)
+		}
+	})
+
+	t.Run("invalid options", func(t *testing.T) {
+		for _, args := range []string{"trend", "trend -runs -1 history.jsonl", "trend -metric x history.jsonl", "trend -runs x history.jsonl"} {
+			buf := new(bytes.Buffer)
+			_, ok := ReadTrendCommandLineOptions(strings.Split(args, " "), buf)
+			assert.False(t, ok, args)
+			assert.NotEqual(t, "", buf.String(), args)
+		}
+	})
+}
+
+func TestReadDiffCommandLineOptions(t *testing.T) {
+	t.Run("valid", func(t *testing.T) {
+		buf := new(bytes.Buffer)
+		opts, ok := ReadDiffCommandLineOptions(strings.Split("diff -package a/b -olddir ../old -skipcode x -metric blocks old.out new.out", " "), buf)
+		if assert.True(t, ok) && assert.Equal(t, "", buf.String()) {
+			assert.Equal(t, "old.out", opts.OldInputFilePath)
+			assert.Equal(t, "new.out", opts.InputFilePath)
+			assert.Equal(t, "a/b", opts.PackagePath)
+			assert.Equal(t, "../old", opts.OldSourceDir)
+			assert.Equal(t, "x", opts.SkipCodePattern.String())
+			assert.Equal(t, metricBlocks, opts.Metric)
+			assert.Equal(t, colorModeAuto, opts.ColorMode)
+		}
+	})
+
+	t.Run("invalid options", func(t *testing.T) {
+		for _, args := range []string{"diff old.out", "diff a b c", "diff -metric x old.out new.out",
+			"diff -color x old.out new.out", "diff -skipfiles ( old.out new.out", "diff -skipcode ( old.out new.out",
+			"diff -minhits x old.out new.out", "diff -minhits 0 old.out new.out", "diff -minhits -5 old.out new.out"} {
+			buf := new(bytes.Buffer)
+			_, ok := ReadDiffCommandLineOptions(strings.Split(args, " "), buf)
+			assert.False(t, ok, args)
+			assert.NotEqual(t, "", buf.String(), args)
+		}
+	})
+}
@@ -0,0 +1,74 @@
+package main
+
+import (
+	"sort"
+	"strings"
+)
+
+// SummaryReportTreeNode is a directory in the hierarchical view of package statistics that is
+// returned by SummaryReport.GetPackageTree.
+type SummaryReportTreeNode struct {
+	// RelativePath is the directory's path relative to the base package; it is "" for the base
+	// package directory itself.
+	RelativePath string
+
+	// Depth is the number of path components in RelativePath.
+	Depth int
+
+	// Coverage is the total coverage of all packages in this directory and its subdirectories.
+	Coverage SummaryReportCoverage
+}
+
+// GetName returns the last component of the node's path, or basePackagePath if it is the root node.
+func (n SummaryReportTreeNode) GetName(basePackagePath string) string {
+	if n.RelativePath == "" {
+		return basePackagePath
+	}
+	return n.RelativePath[strings.LastIndex(n.RelativePath, "/")+1:]
+}
+
+// GetPackageTree adds up the package statistics for each directory under the base package,
+// including intermediate directories that are not packages themselves. The nodes are returned in
+// depth-first order, with the base package directory first. If maxDepth is greater than zero,
+// directories that are more than maxDepth levels below the base package are not included as separate
+// nodes, but their statistics are still included in the totals of their parent directories.
+func (r SummaryReport) GetPackageTree(basePackagePath string, maxDepth int) []SummaryReportTreeNode {
+	nodesByPath := make(map[string]*SummaryReportTreeNode)
+	addTo := func(relPath string, depth int, c SummaryReportCoverage) {
+		n := nodesByPath[relPath]
+		if n == nil {
+			n = &SummaryReportTreeNode{RelativePath: relPath, Depth: depth}
+			nodesByPath[relPath] = n
+		}
+		n.Coverage.add(c)
+	}
+
+	for _, p := range r.Packages {
+		addTo("", 0, p.Coverage)
+		relPath := strings.TrimPrefix(strings.TrimPrefix(p.FullPackagePath, basePackagePath), "/")
+		if relPath == "" {
+			continue
+		}
+		components := strings.Split(relPath, "/")
+		for depth := 1; depth <= len(components) && (maxDepth <= 0 || depth <= maxDepth); depth++ {
+			addTo(strings.Join(components[:depth], "/"), depth, p.Coverage)
+		}
+	}
+
+	ret := make([]SummaryReportTreeNode, 0, len(nodesByPath))
+	for _, n := range nodesByPath {
+		ret = append(ret, *n)
+	}
+	sort.Slice(ret, func(i, j int) bool {
+		// compare component by component, so that "a/b" sorts right after "a" rather than after "a-b"
+		c0 := strings.Split(ret[i].RelativePath, "/")
+		c1 := strings.Split(ret[j].RelativePath, "/")
+		for k := 0; k < len(c0) && k < len(c1); k++ {
+			if c0[k] != c1[k] {
+				return c0[k] < c1[k]
+			}
+		}
+		return len(c0) < len(c1)
+	})
+	return ret
+}
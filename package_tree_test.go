@@ -0,0 +1,44 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func makeTestPackageTreeReport() SummaryReport {
+	return SummaryReport{Packages: []SummaryReportPackage{
+		{FullPackagePath: "base", Coverage: SummaryReportCoverage{TotalStatements: 10, CoveredStatements: 10}},
+		{FullPackagePath: "base/a-b", Coverage: SummaryReportCoverage{TotalStatements: 4, CoveredStatements: 1}},
+		{FullPackagePath: "base/a/b", Coverage: SummaryReportCoverage{TotalStatements: 6, CoveredStatements: 3}},
+		{FullPackagePath: "base/a/b/c", Coverage: SummaryReportCoverage{TotalStatements: 2, CoveredStatements: 0}},
+		{FullPackagePath: "base/a/d", Coverage: SummaryReportCoverage{TotalStatements: 8, CoveredStatements: 8}},
+	}}
+}
+
+func TestGetPackageTree(t *testing.T) {
+	t.Run("unlimited depth", func(t *testing.T) {
+		assert.Equal(t, []SummaryReportTreeNode{
+			{RelativePath: "", Depth: 0, Coverage: SummaryReportCoverage{TotalStatements: 30, CoveredStatements: 22}},
+			{RelativePath: "a", Depth: 1, Coverage: SummaryReportCoverage{TotalStatements: 16, CoveredStatements: 11}},
+			{RelativePath: "a/b", Depth: 2, Coverage: SummaryReportCoverage{TotalStatements: 8, CoveredStatements: 3}},
+			{RelativePath: "a/b/c", Depth: 3, Coverage: SummaryReportCoverage{TotalStatements: 2, CoveredStatements: 0}},
+			{RelativePath: "a/d", Depth: 2, Coverage: SummaryReportCoverage{TotalStatements: 8, CoveredStatements: 8}},
+			{RelativePath: "a-b", Depth: 1, Coverage: SummaryReportCoverage{TotalStatements: 4, CoveredStatements: 1}},
+		}, makeTestPackageTreeReport().GetPackageTree("base", 0))
+	})
+
+	t.Run("limited depth", func(t *testing.T) {
+		assert.Equal(t, []SummaryReportTreeNode{
+			{RelativePath: "", Depth: 0, Coverage: SummaryReportCoverage{TotalStatements: 30, CoveredStatements: 22}},
+			{RelativePath: "a", Depth: 1, Coverage: SummaryReportCoverage{TotalStatements: 16, CoveredStatements: 11}},
+			{RelativePath: "a-b", Depth: 1, Coverage: SummaryReportCoverage{TotalStatements: 4, CoveredStatements: 1}},
+		}, makeTestPackageTreeReport().GetPackageTree("base", 1))
+	})
+}
+
+func TestPackageTreeNodeGetName(t *testing.T) {
+	assert.Equal(t, "base", SummaryReportTreeNode{RelativePath: ""}.GetName("base"))
+	assert.Equal(t, "a", SummaryReportTreeNode{RelativePath: "a"}.GetName("base"))
+	assert.Equal(t, "c", SummaryReportTreeNode{RelativePath: "a/b/c"}.GetName("base"))
+}
@@ -0,0 +1,239 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"text/tabwriter"
+)
+
+// PerTestResult is the result returned by AnalyzePerTestCoverage.
+type PerTestResult struct {
+	// Tests are the names of all of the tests, in alphabetical order. The name of each test is the
+	// filename of its coverage profile without the extension.
+	Tests []string
+
+	// Blocks are all of the code blocks that appeared in any of the profiles, covered or not, sorted
+	// by file path and then by starting position. It does not include any blocks that were skipped
+	// with "-skipfiles" or "-skipcode".
+	Blocks []PerTestBlock
+
+	// Files contains information about each file that appeared in any of the profiles, sorted by
+	// file path. It does not include files that were skipped with "-skipfiles".
+	Files []PerTestFile
+}
+
+// PerTestBlock is block-level information in PerTestResult.
+type PerTestBlock struct {
+	CodeRange      CodeRange
+	StatementCount int
+
+	// Tests are the names of the tests that covered this block, in alphabetical order.
+	Tests []string
+}
+
+// PerTestFile is file-level information in PerTestResult.
+type PerTestFile struct {
+	// FilePath is the file path as it appears in the coverage profile, including the package's
+	// import path.
+	FilePath string
+
+	// TotalStatements is the number of statements in all blocks in this file.
+	TotalStatements int
+
+	// Tests are the tests that covered at least one block in this file, in descending order of
+	// the number of statements they covered.
+	Tests []PerTestFileTest
+}
+
+// PerTestFileTest is the coverage of a file by a single test in PerTestResult.
+type PerTestFileTest struct {
+	Name              string
+	CoveredStatements int
+}
+
+// AnalyzePerTestCoverage reads every coverage profile in the specified directory, each of which
+// is assumed to have been generated by running a single test, and determines which tests covered
+// each block. Each profile is filtered with the same options as for a regular report.
+func AnalyzePerTestCoverage(dirPath string, opts EnforcerOptions) (PerTestResult, error) {
+	var result PerTestResult
+	opts.SkipSourcePostPass = true // avoid parsing every source file again for each profile
+
+	infos, err := ioutil.ReadDir(dirPath)
+	if err != nil {
+		return result, err
+	}
+
+	var analyzedResults []AnalyzerResult
+	skippedRanges := make(map[CodeRange]bool)
+	for _, info := range infos {
+		if info.IsDir() || strings.HasPrefix(info.Name(), ".") {
+			continue
+		}
+		profilePath := filepath.Join(dirPath, info.Name())
+		profile, err := readCoverageProfileFile(profilePath)
+		if err != nil {
+			return result, fmt.Errorf(`unable to read coverage profile "%s" (%s)`, profilePath, err)
+		}
+		analyzed, err := AnalyzeCoverage(profile, opts)
+		if err != nil {
+			return result, fmt.Errorf(`%s (in "%s")`, err, profilePath)
+		}
+		result.Tests = append(result.Tests, strings.TrimSuffix(info.Name(), filepath.Ext(info.Name())))
+		analyzedResults = append(analyzedResults, analyzed)
+		// "-skipcode" is only checked for blocks that were not covered, so a block that was skipped in
+		// one profile might not have been skipped in another.
+		for _, sb := range analyzed.SkippedBlocks {
+			skippedRanges[sb.CodeRange] = true
+		}
+	}
+	if len(result.Tests) == 0 {
+		return result, fmt.Errorf(`no coverage profiles found in "%s"`, dirPath)
+	}
+
+	blockIndexes := make(map[CodeRange]int)
+	fileCoverage := make(map[string]map[string]int)
+	for i, analyzed := range analyzedResults {
+		testName := result.Tests[i]
+		for _, p := range analyzed.Packages {
+			for _, f := range p.Files {
+				for _, b := range f.Blocks {
+					if skippedRanges[b.CodeRange] {
+						continue
+					}
+					j, ok := blockIndexes[b.CodeRange]
+					if !ok {
+						j = len(result.Blocks)
+						blockIndexes[b.CodeRange] = j
+						result.Blocks = append(result.Blocks, PerTestBlock{CodeRange: b.CodeRange, StatementCount: b.StatementCount})
+					}
+					if !isCovered(b.CoverageCount, getMinHits(opts)) {
+						continue
+					}
+					result.Blocks[j].Tests = append(result.Blocks[j].Tests, testName)
+					if fileCoverage[b.CodeRange.FilePath] == nil {
+						fileCoverage[b.CodeRange.FilePath] = make(map[string]int)
+					}
+					fileCoverage[b.CodeRange.FilePath][testName] += b.StatementCount
+				}
+			}
+		}
+	}
+
+	sort.Strings(result.Tests)
+	sort.SliceStable(result.Blocks, func(i, j int) bool {
+		a, b := result.Blocks[i].CodeRange, result.Blocks[j].CodeRange
+		if a.FilePath != b.FilePath {
+			return a.FilePath < b.FilePath
+		}
+		return a.StartLine < b.StartLine || (a.StartLine == b.StartLine && a.StartColumn < b.StartColumn)
+	})
+
+	for _, b := range result.Blocks {
+		sort.Strings(b.Tests)
+		n := len(result.Files)
+		if n == 0 || result.Files[n-1].FilePath != b.CodeRange.FilePath {
+			result.Files = append(result.Files, PerTestFile{FilePath: b.CodeRange.FilePath})
+			n++
+		}
+		result.Files[n-1].TotalStatements += b.StatementCount
+	}
+	for i, f := range result.Files {
+		for name, covered := range fileCoverage[f.FilePath] {
+			f.Tests = append(f.Tests, PerTestFileTest{Name: name, CoveredStatements: covered})
+		}
+		sort.Slice(f.Tests, func(j, k int) bool {
+			if f.Tests[j].CoveredStatements != f.Tests[k].CoveredStatements {
+				return f.Tests[j].CoveredStatements > f.Tests[k].CoveredStatements
+			}
+			return f.Tests[j].Name < f.Tests[k].Name
+		})
+		result.Files[i] = f
+	}
+
+	return result, nil
+}
+
+// GetUniqueBlocks returns the blocks that were covered by exactly one test.
+func (r PerTestResult) GetUniqueBlocks() []PerTestBlock {
+	var ret []PerTestBlock
+	for _, b := range r.Blocks {
+		if len(b.Tests) == 1 {
+			ret = append(ret, b)
+		}
+	}
+	return ret
+}
+
+// GetRedundantTests returns the names of the tests that did not cover any block that was not also
+// covered by some other test, in alphabetical order. Any one of these tests could be removed
+// without reducing coverage, but removing more than one of them might reduce coverage.
+func (r PerTestResult) GetRedundantTests() []string {
+	hasUniqueBlock := make(map[string]bool)
+	for _, b := range r.GetUniqueBlocks() {
+		hasUniqueBlock[b.Tests[0]] = true
+	}
+	var ret []string
+	for _, t := range r.Tests {
+		if !hasUniqueBlock[t] {
+			ret = append(ret, t)
+		}
+	}
+	return ret
+}
+
+// Output writes the per-test coverage report in text format.
+func (r PerTestResult) Output(writer io.Writer) {
+	fmt.Fprintf(writer, "Analyzed coverage profiles for %d test(s)\n\n", len(r.Tests))
+
+	uniqueBlocks := r.GetUniqueBlocks()
+	if len(uniqueBlocks) == 0 {
+		fmt.Fprintln(writer, "No blocks were covered by only one test")
+	} else {
+		fmt.Fprintln(writer, "Blocks covered by only one test:")
+		for _, b := range uniqueBlocks {
+			fmt.Fprintf(writer, "%s %d-%d (%s): %s\n", b.CodeRange.FilePath, b.CodeRange.StartLine, b.CodeRange.EndLine,
+				describeStatementCount(b.StatementCount), b.Tests[0])
+		}
+	}
+	fmt.Fprintln(writer)
+
+	redundantTests := r.GetRedundantTests()
+	if len(redundantTests) == 0 {
+		fmt.Fprintln(writer, "Every test adds unique coverage")
+	} else {
+		fmt.Fprintln(writer, "Tests that add no unique coverage:")
+		for _, t := range redundantTests {
+			fmt.Fprintln(writer, t)
+		}
+	}
+	fmt.Fprintln(writer)
+
+	fmt.Fprintln(writer, "Tests for each file:")
+	for _, f := range r.Files {
+		fmt.Fprintf(writer, "%s (%s):\n", f.FilePath, describeStatementCount(f.TotalStatements))
+		if len(f.Tests) == 0 {
+			fmt.Fprintln(writer, "  (not covered by any test)")
+			continue
+		}
+		tw := tabwriter.NewWriter(writer, 1, 4, 1, ' ', 0)
+		for _, t := range f.Tests {
+			c := SummaryReportCoverage{TotalStatements: f.TotalStatements, CoveredStatements: t.CoveredStatements}
+			fmt.Fprintf(tw, "  %s\t%d/%d\t(%d%%)\t\n", t.Name, t.CoveredStatements, f.TotalStatements, c.GetCoveredPercent())
+		}
+		tw.Flush()
+	}
+}
+
+func readCoverageProfileFile(filePath string) (*CoverageProfile, error) {
+	f, err := os.Open(filePath)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return ReadCoverageProfile(f)
+}
@@ -0,0 +1,202 @@
+package main
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"regexp"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testDataPerTestDir = "pertest"
+
+func TestAnalyzePerTestCoverage(t *testing.T) {
+	samplePath := testDataPackagePath + "/gosource/sample.go"
+
+	t.Run("attributes blocks to tests", func(t *testing.T) {
+		inTestDataDir(func() {
+			result, err := AnalyzePerTestCoverage(testDataPerTestDir, testBaseOptions)
+			require.NoError(t, err)
+
+			assert.Equal(t, []string{"TestAdd", "TestAddAndGet", "TestGet", "TestInc", "TestIncNegative"}, result.Tests)
+			require.Len(t, result.Blocks, 7)
+			assert.Equal(t, PerTestBlock{
+				CodeRange:      CodeRange{samplePath, 3, 24, 5, 2},
+				StatementCount: 1,
+				Tests:          []string{"TestAdd", "TestAddAndGet"},
+			}, result.Blocks[0])
+			assert.Equal(t, []string{"TestIncNegative"}, result.Blocks[2].Tests)
+
+			assert.Equal(t, []PerTestFile{
+				{
+					FilePath:        samplePath,
+					TotalStatements: 7,
+					Tests: []PerTestFileTest{
+						{Name: "TestAddAndGet", CoveredStatements: 4},
+						{Name: "TestGet", CoveredStatements: 3},
+						{Name: "TestInc", CoveredStatements: 2},
+						{Name: "TestIncNegative", CoveredStatements: 2},
+						{Name: "TestAdd", CoveredStatements: 1},
+					},
+				},
+			}, result.Files)
+		})
+	})
+
+	t.Run("finds unique blocks and redundant tests", func(t *testing.T) {
+		inTestDataDir(func() {
+			result, err := AnalyzePerTestCoverage(testDataPerTestDir, testBaseOptions)
+			require.NoError(t, err)
+
+			var uniqueLines []int
+			for _, b := range result.GetUniqueBlocks() {
+				uniqueLines = append(uniqueLines, b.CodeRange.StartLine)
+			}
+			assert.Equal(t, []int{10, 13}, uniqueLines)
+			assert.Equal(t, []string{"TestAdd", "TestAddAndGet", "TestGet"}, result.GetRedundantTests())
+		})
+	})
+
+	t.Run("skipped blocks are not attributed", func(t *testing.T) {
+		inTestDataDir(func() {
+			opts := testBaseOptions
+			opts.SkipCodePattern = regexp.MustCompile("panic")
+			result, err := AnalyzePerTestCoverage(testDataPerTestDir, opts)
+			require.NoError(t, err)
+
+			assert.Len(t, result.Blocks, 6)
+			assert.Equal(t, 6, result.Files[0].TotalStatements)
+			assert.Equal(t, []string{"TestAdd", "TestAddAndGet", "TestGet", "TestIncNegative"}, result.GetRedundantTests())
+		})
+	})
+
+	t.Run("directory with no profiles", func(t *testing.T) {
+		dir, err := ioutil.TempDir("", "pertest")
+		require.NoError(t, err)
+		defer os.RemoveAll(dir)
+		_, err = AnalyzePerTestCoverage(dir, testBaseOptions)
+		assert.EqualError(t, err, `no coverage profiles found in "`+dir+`"`)
+	})
+
+	t.Run("directory that does not exist", func(t *testing.T) {
+		_, err := AnalyzePerTestCoverage("no-such-directory", testBaseOptions)
+		assert.Error(t, err)
+	})
+
+	t.Run("subdirectories and hidden files are ignored", func(t *testing.T) {
+		withTempDir(func(dir string) {
+			require.NoError(t, os.Mkdir(filepath.Join(dir, "subdir"), 0755))
+			require.NoError(t, ioutil.WriteFile(filepath.Join(dir, ".hidden"), []byte("not a profile"), 0644))
+			copyTestDataFile(t, testDataMainFile, filepath.Join(dir, "TestA.out"))
+
+			result, err := AnalyzePerTestCoverage(dir, testBaseOptions)
+			require.NoError(t, err)
+			assert.Equal(t, []string{"TestA"}, result.Tests)
+		})
+	})
+
+	t.Run("profile that cannot be analyzed", func(t *testing.T) {
+		inTestDataDir(func() {
+			opts := testBaseOptions
+			opts.MinHits = 2
+			_, err := AnalyzePerTestCoverage(testDataPerTestDir, opts)
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), `(in "`+filepath.Join(testDataPerTestDir, "TestAdd.out")+`")`)
+		})
+	})
+
+	t.Run("file that is not a profile", func(t *testing.T) {
+		inTestDataDir(func() {
+			_, err := AnalyzePerTestCoverage("gosource", testBaseOptions)
+			assert.Error(t, err)
+		})
+	})
+}
+
+func TestPerTestResultOutput(t *testing.T) {
+	inTestDataDir(func() {
+		result, err := AnalyzePerTestCoverage(testDataPerTestDir, testBaseOptions)
+		require.NoError(t, err)
+
+		buf := new(bytes.Buffer)
+		result.Output(buf)
+		output := regexp.MustCompile(" +").ReplaceAllString(buf.String(), " ")
+		output = regexp.MustCompile(" \n").ReplaceAllString(output, "\n")
+		assert.Equal(t, `Analyzed coverage profiles for 5 test(s)
+
+Blocks covered by only one test:
+base-package/gosource/sample.go 10-12 (1 statement): TestIncNegative
+base-package/gosource/sample.go 13-14 (1 statement): TestInc
+
+Tests that add no unique coverage:
+TestAdd
+TestAddAndGet
+TestGet
+
+Tests for each file:
+base-package/gosource/sample.go (7 statements):
+ TestAddAndGet 4/7 (57%)
+ TestGet 3/7 (42%)
+ TestInc 2/7 (28%)
+ TestIncNegative 2/7 (28%)
+ TestAdd 1/7 (14%)
+`, output)
+	})
+}
+
+func TestPerTestResultOutputWithNoRedundantTests(t *testing.T) {
+	withTempDir(func(dir string) {
+		copyTestDataFile(t, testDataMainFile, filepath.Join(dir, "TestA.out"))
+		result, err := AnalyzePerTestCoverage(dir, testBaseOptions)
+		require.NoError(t, err)
+
+		buf := new(bytes.Buffer)
+		result.Output(buf)
+		output := regexp.MustCompile(" +\n").ReplaceAllString(buf.String(), "\n")
+		assert.Equal(t, `Analyzed coverage profiles for 1 test(s)
+
+Blocks covered by only one test:
+base-package/third 3-4 (2 statements): TestA
+
+Every test adds unique coverage
+
+Tests for each file:
+base-package/first (3 statements):
+  (not covered by any test)
+base-package/otherpackage/first (1 statement):
+  (not covered by any test)
+base-package/second (5 statements):
+  (not covered by any test)
+base-package/third (6 statements):
+  TestA 2/6 (33%)
+`, output)
+	})
+}
+
+func TestPerTestResultOutputWithNoUniqueBlocks(t *testing.T) {
+	withTempDir(func(dir string) {
+		copyTestDataFile(t, filepath.Join(testDataPerTestDir, "TestInc.out"), filepath.Join(dir, "TestA.out"))
+		copyTestDataFile(t, filepath.Join(testDataPerTestDir, "TestInc.out"), filepath.Join(dir, "TestB.out"))
+		result, err := AnalyzePerTestCoverage(dir, testBaseOptions)
+		require.NoError(t, err)
+
+		buf := new(bytes.Buffer)
+		result.Output(buf)
+		assert.Contains(t, buf.String(), "No blocks were covered by only one test\n\nTests that add no unique coverage:\nTestA\nTestB\n")
+	})
+}
+
+func TestReadCoverageProfileFileNotFound(t *testing.T) {
+	_, err := readCoverageProfileFile("no-such-file")
+	assert.Error(t, err)
+}
+
+func copyTestDataFile(t *testing.T, name, destPath string) {
+	data, err := ioutil.ReadFile(filepath.Join("testdata", name))
+	require.NoError(t, err)
+	require.NoError(t, ioutil.WriteFile(destPath, data, 0644))
+}
@@ -0,0 +1,61 @@
+package main
+
+import (
+	"io"
+	"path"
+	"sort"
+	"strings"
+)
+
+// reportWriter is a function that writes the results of a coverage scan in some output format.
+type reportWriter func(writer io.Writer, report SummaryReport, result AnalyzerResult, opts EnforcerOptions) error
+
+// reportFormats maps each supported value of the "-format" option to the function that writes it.
+var reportFormats = map[string]reportWriter{
+	"text":      writeTextReport,
+	"json":      WriteJSONReport,
+	"junit":     WriteJUnitReport,
+	"cobertura": WriteCoberturaReport,
+	"lcov":      WriteLCOVReport,
+	"html":      WriteHTMLReport,
+	"markdown":  WriteMarkdownReport,
+	"github":    WriteGitHubReport,
+	"sarif":     WriteSARIFReport,
+	"compiler":  WriteCompilerReport,
+	"template":  WriteTemplateReport,
+}
+
+// WriteReport writes the results of a coverage scan in the specified output format.
+func WriteReport(
+	writer io.Writer,
+	format string,
+	report SummaryReport,
+	result AnalyzerResult,
+	opts EnforcerOptions,
+) error {
+	return reportFormats[format](writer, report, result, opts)
+}
+
+// getPackageResultsByPath returns a map of the package results in AnalyzerResult, keyed by their
+// full import paths (SummaryReportPackage.FullPackagePath).
+func getPackageResultsByPath(result AnalyzerResult, opts EnforcerOptions) map[string]AnalyzerPackageResult {
+	ret := make(map[string]AnalyzerPackageResult, len(result.Packages))
+	for _, p := range result.Packages {
+		ret[path.Join(opts.PackagePath, p.RelativePath)] = p
+	}
+	return ret
+}
+
+func getReportFormatNames() string {
+	var names []string
+	for name := range reportFormats {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return strings.Join(names, ", ")
+}
+
+func writeTextReport(writer io.Writer, report SummaryReport, result AnalyzerResult, opts EnforcerOptions) error {
+	report.Output(writer, opts)
+	return nil
+}
@@ -0,0 +1,23 @@
+package main
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTextReportFormat(t *testing.T) {
+	withValidTestProfile(testDataReportNotPassFile, func(cp *CoverageProfile) {
+		opts := testBaseOptions
+		opts.ShowCode = true
+		result, err := AnalyzeCoverage(cp, opts)
+		require.NoError(t, err)
+		report := NewSummaryReport(result, opts)
+
+		expected := new(bytes.Buffer)
+		report.Output(expected, opts)
+		assert.Equal(t, expected.String(), writeReportForTest(t, cp, opts, "text"))
+	})
+}
@@ -0,0 +1,134 @@
+package main
+
+import (
+	"sort"
+	"strings"
+)
+
+// These are the allowable values for the "-sort" option.
+const (
+	sortByPath      = "path"
+	sortByUncovered = "uncovered"
+	sortByPercent   = "percent"
+	sortBySize      = "size"
+)
+
+var sortOrders = []string{sortByPath, sortByUncovered, sortByPercent, sortBySize}
+
+func getSortOrderNames() string {
+	return strings.Join(sortOrders, ", ")
+}
+
+func isValidSortOrder(s string) bool {
+	for _, o := range sortOrders {
+		if s == o {
+			return true
+		}
+	}
+	return false
+}
+
+// summaryReportFileRow is a file in the stats table when files are listed without their packages.
+type summaryReportFileRow struct {
+	FilePath string
+	Coverage SummaryReportCoverage
+}
+
+// getEffectiveSortOrder returns the sort order that is used for the stats tables. If "-top" was
+// specified without "-sort", the rows are ranked by coverage percentage, since otherwise the
+// "worst" rows would just be the first ones alphabetically.
+func getEffectiveSortOrder(opts EnforcerOptions) string {
+	if opts.SortOrder == "" || opts.SortOrder == sortByPath {
+		if opts.TopCount > 0 {
+			return sortByPercent
+		}
+		return sortByPath
+	}
+	return opts.SortOrder
+}
+
+// isWorseCoverage returns true if a should be listed before b in the given sort order, comparing
+// the given metric. For sortByPath, it always returns false, so that a stable sort preserves the
+// existing order.
+func isWorseCoverage(a, b SummaryReportCoverage, sortOrder, metric string) bool {
+	aCovered, aTotal := a.GetMetric(metric)
+	bCovered, bTotal := b.GetMetric(metric)
+	switch sortOrder {
+	case sortByUncovered:
+		return aTotal-aCovered > bTotal-bCovered
+	case sortByPercent:
+		return getCoveredRatio(aCovered, aTotal) < getCoveredRatio(bCovered, bTotal)
+	case sortBySize:
+		return aTotal > bTotal
+	default:
+		return false
+	}
+}
+
+func getCoveredRatio(covered, total int) float64 {
+	if total == 0 {
+		return 1
+	}
+	return float64(covered) / float64(total)
+}
+
+// getSortedPackages returns the packages in the order specified by "-sort", limited to the number
+// specified by "-top".
+func (r SummaryReport) getSortedPackages(opts EnforcerOptions) []SummaryReportPackage {
+	sortOrder := getEffectiveSortOrder(opts)
+	ret := append([]SummaryReportPackage(nil), r.Packages...)
+	sort.SliceStable(ret, func(i, j int) bool {
+		return isWorseCoverage(ret[i].Coverage, ret[j].Coverage, sortOrder, opts.Metric)
+	})
+	if opts.TopCount > 0 && len(ret) > opts.TopCount {
+		ret = ret[:opts.TopCount]
+	}
+	return ret
+}
+
+// getSortedFiles returns the files of a package in the order specified by "-sort", limited to the
+// number specified by "-top".
+func getSortedFiles(p SummaryReportPackage, opts EnforcerOptions) []SummaryReportFile {
+	sortOrder := getEffectiveSortOrder(opts)
+	ret := append([]SummaryReportFile(nil), p.Files...)
+	sort.SliceStable(ret, func(i, j int) bool {
+		return isWorseCoverage(ret[i].Coverage, ret[j].Coverage, sortOrder, opts.Metric)
+	})
+	if opts.TopCount > 0 && len(ret) > opts.TopCount {
+		ret = ret[:opts.TopCount]
+	}
+	return ret
+}
+
+// getSortedFileRows returns the files of all packages in the order specified by "-sort", limited
+// to the number specified by "-top". Unlike getSortedFiles, files from different packages are
+// ranked against each other.
+func (r SummaryReport) getSortedFileRows(opts EnforcerOptions) []summaryReportFileRow {
+	sortOrder := getEffectiveSortOrder(opts)
+	var ret []summaryReportFileRow
+	for _, p := range r.Packages {
+		for _, f := range p.Files {
+			ret = append(ret, summaryReportFileRow{FilePath: p.FullPackagePath + "/" + f.FileName, Coverage: f.Coverage})
+		}
+	}
+	sort.SliceStable(ret, func(i, j int) bool {
+		return isWorseCoverage(ret[i].Coverage, ret[j].Coverage, sortOrder, opts.Metric)
+	})
+	if opts.TopCount > 0 && len(ret) > opts.TopCount {
+		ret = ret[:opts.TopCount]
+	}
+	return ret
+}
+
+// getSortedUncoveredBlocks returns the uncovered blocks in the order specified by "-sort". Since
+// every block is entirely uncovered, any order other than sortByPath puts the blocks with the most
+// statements first.
+func (r SummaryReport) getSortedUncoveredBlocks(opts EnforcerOptions) []UncoveredBlock {
+	ret := append([]UncoveredBlock(nil), r.UncoveredBlocks...)
+	if opts.SortOrder != "" && opts.SortOrder != sortByPath {
+		sort.SliceStable(ret, func(i, j int) bool {
+			return ret[i].StatementCount > ret[j].StatementCount
+		})
+	}
+	return ret
+}
@@ -0,0 +1,168 @@
+package main
+
+import (
+	"bytes"
+	"regexp"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestIsWorseCoverage(t *testing.T) {
+	a := SummaryReportCoverage{TotalStatements: 10, CoveredStatements: 5}
+	b := SummaryReportCoverage{TotalStatements: 4, CoveredStatements: 1}
+	empty := SummaryReportCoverage{}
+
+	assert.True(t, isWorseCoverage(a, b, sortByUncovered, metricStatements))
+	assert.False(t, isWorseCoverage(b, a, sortByUncovered, metricStatements))
+	assert.True(t, isWorseCoverage(b, a, sortByPercent, metricStatements))
+	assert.False(t, isWorseCoverage(a, b, sortByPercent, metricStatements))
+	assert.True(t, isWorseCoverage(a, empty, sortByPercent, metricStatements))
+	assert.False(t, isWorseCoverage(empty, a, sortByPercent, metricStatements))
+	assert.True(t, isWorseCoverage(a, b, sortBySize, metricStatements))
+	assert.False(t, isWorseCoverage(a, b, sortByPath, metricStatements))
+	assert.False(t, isWorseCoverage(b, a, sortByPath, metricStatements))
+}
+
+func TestIsWorseCoverageWithMetric(t *testing.T) {
+	a := SummaryReportCoverage{TotalStatements: 10, CoveredStatements: 5, TotalLines: 4, CoveredLines: 4}
+	b := SummaryReportCoverage{TotalStatements: 4, CoveredStatements: 1, TotalLines: 8, CoveredLines: 2}
+
+	assert.True(t, isWorseCoverage(b, a, sortByPercent, metricStatements))
+	assert.True(t, isWorseCoverage(b, a, sortByPercent, metricLines))
+	assert.True(t, isWorseCoverage(a, b, sortBySize, metricStatements))
+	assert.True(t, isWorseCoverage(b, a, sortBySize, metricLines))
+}
+
+func TestGetEffectiveSortOrder(t *testing.T) {
+	assert.Equal(t, sortByPath, getEffectiveSortOrder(EnforcerOptions{}))
+	assert.Equal(t, sortByPath, getEffectiveSortOrder(EnforcerOptions{SortOrder: sortByPath}))
+	assert.Equal(t, sortByPercent, getEffectiveSortOrder(EnforcerOptions{SortOrder: sortByPath, TopCount: 2}))
+	assert.Equal(t, sortBySize, getEffectiveSortOrder(EnforcerOptions{SortOrder: sortBySize, TopCount: 2}))
+}
+
+func TestReportOutputWithSorting(t *testing.T) {
+	doTest := func(t *testing.T, opts EnforcerOptions, expected string) {
+		withValidTestProfile(testDataReportNotPassFile, func(cp *CoverageProfile) {
+			result, err := AnalyzeCoverage(cp, opts)
+			require.NoError(t, err)
+			report := NewSummaryReport(result, opts)
+
+			buf := new(bytes.Buffer)
+			report.Output(buf, opts)
+			s := regexp.MustCompile(" +").ReplaceAllString(buf.String(), " ")
+			s = regexp.MustCompile(" \n").ReplaceAllString(s, "\n")
+			assert.Equal(t, expected, s)
+		})
+	}
+
+	t.Run("files sorted by uncovered statements", func(t *testing.T) {
+		opts := testBaseOptions
+		opts.ShowFileStats = true
+		opts.SortOrder = sortByUncovered
+		doTest(t, opts, `base-package/otherpackage/first 0/7 (0%)
+base-package/first 2/4 (50%)
+base-package/second 4/4 (100%)
+base-package/third 0/0 (100%)
+
+Uncovered blocks detected:
+base-package/otherpackage/first 1-3
+base-package/first 3-4
+`)
+	})
+
+	t.Run("files sorted by size", func(t *testing.T) {
+		opts := testBaseOptions
+		opts.ShowFileStats = true
+		opts.SortOrder = sortBySize
+		doTest(t, opts, `base-package/otherpackage/first 0/7 (0%)
+base-package/first 2/4 (50%)
+base-package/second 4/4 (100%)
+base-package/third 0/0 (100%)
+
+Uncovered blocks detected:
+base-package/otherpackage/first 1-3
+base-package/first 3-4
+`)
+	})
+
+	t.Run("-top with default sort ranks by percent", func(t *testing.T) {
+		opts := testBaseOptions
+		opts.ShowFileStats = true
+		opts.SortOrder = sortByPath
+		opts.TopCount = 2
+		doTest(t, opts, `base-package/otherpackage/first 0/7 (0%)
+base-package/first 2/4 (50%)
+
+Uncovered blocks detected:
+base-package/first 3-4
+base-package/otherpackage/first 1-3
+`)
+	})
+
+	t.Run("-top with package and file stats", func(t *testing.T) {
+		opts := testBaseOptions
+		opts.ShowPackageStats = true
+		opts.ShowFileStats = true
+		opts.TopCount = 1
+		doTest(t, opts, `base-package/otherpackage 0/7 (0%)
+ first 0/7 (0%)
+
+Uncovered blocks detected:
+base-package/first 3-4
+base-package/otherpackage/first 1-3
+`)
+	})
+
+	t.Run("-top limits the files within each package", func(t *testing.T) {
+		opts := testBaseOptions
+		opts.ShowPackageStats = true
+		opts.ShowFileStats = true
+		opts.TopCount = 2
+		doTest(t, opts, `base-package/otherpackage 0/7 (0%)
+ first 0/7 (0%)
+base-package 6/8 (75%)
+ first 2/4 (50%)
+ second 4/4 (100%)
+
+Uncovered blocks detected:
+base-package/first 3-4
+base-package/otherpackage/first 1-3
+`)
+	})
+
+	t.Run("-maxblocks", func(t *testing.T) {
+		opts := testBaseOptions
+		opts.MaxBlocks = 1
+		doTest(t, opts, `Uncovered blocks detected:
+base-package/first 3-4
+... and 1 more
+`)
+	})
+
+	t.Run("-maxblocks with -showcode", func(t *testing.T) {
+		opts := testBaseOptions
+		opts.MaxBlocks = 1
+		opts.ShowCode = true
+		opts.SortOrder = sortBySize
+		doTest(t, opts, `Uncovered blocks detected:
+
+base-package/otherpackage/first 1-3
+1>	other package first file line 1
+2>	other package first file line 2
+3>	other package first file line 3
+
+... and 1 more
+`)
+	})
+
+	t.Run("-maxblocks greater than block count", func(t *testing.T) {
+		opts := testBaseOptions
+		opts.MaxBlocks = 2
+		doTest(t, opts, `Uncovered blocks detected:
+base-package/first 3-4
+base-package/otherpackage/first 1-3
+`)
+	})
+}
@@ -0,0 +1,154 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+)
+
+const (
+	sarifSchemaURI        = "https://json.schemastore.org/sarif-2.1.0.json"
+	sarifVersion          = "2.1.0"
+	sarifToolName         = "go-coverage-enforcer"
+	sarifToolURI          = "https://github.com/launchdarkly-labs/go-coverage-enforcer"
+	sarifUncoveredBlockID = "uncovered-block"
+)
+
+// sarifRules contains the definition of each rule that can appear in a SARIF log, keyed by rule ID.
+// The uncovered-blocks rule of the report is represented by the uncovered-block rule, which has a
+// result for each block.
+var sarifRules = map[string]sarifRule{
+	sarifUncoveredBlockID: {
+		ID:               sarifUncoveredBlockID,
+		ShortDescription: sarifMessage{Text: "Code block is not covered by tests"},
+		FullDescription: sarifMessage{Text: "A block of code was not executed by any test, or was executed " +
+			"fewer times than the minimum specified with -minhits."},
+	},
+	partialLinesRuleName: {
+		ID:               partialLinesRuleName,
+		ShortDescription: sarifMessage{Text: "Too many partially covered lines"},
+		FullDescription: sarifMessage{Text: "More lines contained both covered and uncovered code than the " +
+			"maximum specified with -maxpartial."},
+	},
+	staleProfileRuleName: {
+		ID:               staleProfileRuleName,
+		ShortDescription: sarifMessage{Text: "Coverage profile does not match the source code"},
+		FullDescription: sarifMessage{Text: "Some code ranges in the coverage profile do not match the current " +
+			"source code, so the profile may be out of date and the coverage results may be wrong."},
+	},
+}
+
+type sarifLog struct {
+	Schema  string     `json:"$schema"`
+	Version string     `json:"version"`
+	Runs    []sarifRun `json:"runs"`
+}
+
+type sarifRun struct {
+	Tool    sarifTool     `json:"tool"`
+	Results []sarifResult `json:"results"`
+}
+
+type sarifTool struct {
+	Driver sarifDriver `json:"driver"`
+}
+
+type sarifDriver struct {
+	Name           string      `json:"name"`
+	InformationURI string      `json:"informationUri"`
+	Rules          []sarifRule `json:"rules"`
+}
+
+type sarifRule struct {
+	ID               string       `json:"id"`
+	ShortDescription sarifMessage `json:"shortDescription"`
+	FullDescription  sarifMessage `json:"fullDescription"`
+}
+
+type sarifResult struct {
+	RuleID    string          `json:"ruleId"`
+	Level     string          `json:"level"`
+	Message   sarifMessage    `json:"message"`
+	Locations []sarifLocation `json:"locations,omitempty"`
+}
+
+type sarifMessage struct {
+	Text string `json:"text"`
+}
+
+type sarifLocation struct {
+	PhysicalLocation sarifPhysicalLocation `json:"physicalLocation"`
+}
+
+type sarifPhysicalLocation struct {
+	ArtifactLocation sarifArtifactLocation `json:"artifactLocation"`
+	Region           sarifRegion           `json:"region"`
+}
+
+type sarifArtifactLocation struct {
+	URI string `json:"uri"`
+}
+
+type sarifRegion struct {
+	StartLine   int `json:"startLine"`
+	StartColumn int `json:"startColumn"`
+	EndLine     int `json:"endLine"`
+	EndColumn   int `json:"endColumn"`
+}
+
+// WriteSARIFReport writes the report data as a SARIF 2.1.0 log. Each uncovered block is a result
+// with the rule ID "uncovered-block" and the block's location. Every other rule in the report is
+// also defined in the log, using the rule's name as its ID and the description from sarifRules, and
+// produces a result without a location if it did not pass.
+//
+// File paths are made relative to the base package directory.
+func WriteSARIFReport(writer io.Writer, report SummaryReport, result AnalyzerResult, opts EnforcerOptions) error {
+	run := sarifRun{
+		Tool: sarifTool{Driver: sarifDriver{
+			Name:           sarifToolName,
+			InformationURI: sarifToolURI,
+			Rules:          []sarifRule{sarifRules[sarifUncoveredBlockID]},
+		}},
+		Results: make([]sarifResult, 0, len(report.UncoveredBlocks)),
+	}
+
+	for _, b := range report.UncoveredBlocks {
+		r := b.CodeRange
+		run.Results = append(run.Results, sarifResult{
+			RuleID:  sarifUncoveredBlockID,
+			Level:   "error",
+			Message: sarifMessage{Text: fmt.Sprintf("Lines %d-%d are not covered by tests", r.StartLine, r.EndLine)},
+			Locations: []sarifLocation{{PhysicalLocation: sarifPhysicalLocation{
+				ArtifactLocation: sarifArtifactLocation{URI: r.GetRelativeFilePath(opts.PackagePath)},
+				Region: sarifRegion{
+					StartLine:   r.StartLine,
+					StartColumn: r.StartColumn,
+					EndLine:     r.EndLine,
+					EndColumn:   r.EndColumn,
+				},
+			}}},
+		})
+	}
+
+	for _, rule := range report.Rules {
+		if rule.Name == uncoveredBlocksRuleName {
+			continue // already represented by the individual uncovered-block results
+		}
+		sr, ok := sarifRules[rule.Name]
+		if !ok {
+			sr = sarifRule{ID: rule.Name, ShortDescription: sarifMessage{Text: rule.Name}, FullDescription: sarifMessage{Text: rule.Name}}
+		}
+		run.Tool.Driver.Rules = append(run.Tool.Driver.Rules, sr)
+		if !rule.Pass {
+			run.Results = append(run.Results, sarifResult{
+				RuleID:  rule.Name,
+				Level:   "error",
+				Message: sarifMessage{Text: rule.Message},
+			})
+		}
+	}
+
+	enc := json.NewEncoder(writer)
+	enc.SetIndent("", "  ")
+	return enc.Encode(sarifLog{Schema: sarifSchemaURI, Version: sarifVersion, Runs: []sarifRun{run}})
+}
@@ -0,0 +1,92 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSARIFReport(t *testing.T) {
+	t.Run("report from data with coverage gaps", func(t *testing.T) {
+		withValidTestProfile(testDataReportNotPassFile, func(cp *CoverageProfile) {
+			var log sarifLog
+			require.NoError(t, json.Unmarshal([]byte(writeReportForTest(t, cp, testBaseOptions, "sarif")), &log))
+
+			assert.Equal(t, sarifSchemaURI, log.Schema)
+			assert.Equal(t, "2.1.0", log.Version)
+			require.Len(t, log.Runs, 1)
+			run := log.Runs[0]
+			assert.Equal(t, sarifToolName, run.Tool.Driver.Name)
+			assert.Equal(t, []sarifRule{sarifRules[sarifUncoveredBlockID]}, run.Tool.Driver.Rules)
+			require.Len(t, run.Results, 2)
+			assert.Equal(t, sarifResult{
+				RuleID:  sarifUncoveredBlockID,
+				Level:   "error",
+				Message: sarifMessage{Text: "Lines 3-4 are not covered by tests"},
+				Locations: []sarifLocation{{PhysicalLocation: sarifPhysicalLocation{
+					ArtifactLocation: sarifArtifactLocation{URI: "first"},
+					Region:           sarifRegion{StartLine: 3, StartColumn: 1, EndLine: 4, EndColumn: 1},
+				}}},
+			}, run.Results[0])
+			assert.Equal(t, "otherpackage/first", run.Results[1].Locations[0].PhysicalLocation.ArtifactLocation.URI)
+		})
+	})
+
+	t.Run("rule definitions", func(t *testing.T) {
+		report := SummaryReport{Rules: []SummaryReportRule{
+			{Name: uncoveredBlocksRuleName, Pass: true, Message: "no uncovered blocks"},
+			{Name: partialLinesRuleName, Pass: true, Message: "no partially covered lines"},
+			{Name: staleProfileRuleName, Pass: true, Message: "coverage profile matches source code"},
+		}}
+		buf := new(bytes.Buffer)
+		require.NoError(t, WriteReport(buf, "sarif", report, AnalyzerResult{}, testBaseOptions))
+		var log sarifLog
+		require.NoError(t, json.Unmarshal(buf.Bytes(), &log))
+
+		run := log.Runs[0]
+		assert.Equal(t, []sarifRule{
+			{
+				ID:               "uncovered-block",
+				ShortDescription: sarifMessage{Text: "Code block is not covered by tests"},
+				FullDescription: sarifMessage{Text: "A block of code was not executed by any test, or was executed " +
+					"fewer times than the minimum specified with -minhits."},
+			},
+			{
+				ID:               "partial-lines",
+				ShortDescription: sarifMessage{Text: "Too many partially covered lines"},
+				FullDescription: sarifMessage{Text: "More lines contained both covered and uncovered code than the " +
+					"maximum specified with -maxpartial."},
+			},
+			{
+				ID:               "stale-profile",
+				ShortDescription: sarifMessage{Text: "Coverage profile does not match the source code"},
+				FullDescription: sarifMessage{Text: "Some code ranges in the coverage profile do not match the current " +
+					"source code, so the profile may be out of date and the coverage results may be wrong."},
+			},
+		}, run.Tool.Driver.Rules)
+		assert.Equal(t, []sarifResult{}, run.Results)
+	})
+
+	t.Run("rules other than uncovered blocks", func(t *testing.T) {
+		report := SummaryReport{Rules: []SummaryReportRule{
+			{Name: uncoveredBlocksRuleName, Pass: true, Message: "no uncovered blocks"},
+			{Name: "other-rule", Pass: false, Message: "something was wrong"},
+		}}
+		buf := new(bytes.Buffer)
+		require.NoError(t, WriteReport(buf, "sarif", report, AnalyzerResult{}, testBaseOptions))
+		var log sarifLog
+		require.NoError(t, json.Unmarshal(buf.Bytes(), &log))
+
+		run := log.Runs[0]
+		assert.Equal(t, []sarifRule{
+			sarifRules[sarifUncoveredBlockID],
+			{ID: "other-rule", ShortDescription: sarifMessage{Text: "other-rule"}, FullDescription: sarifMessage{Text: "other-rule"}},
+		}, run.Tool.Driver.Rules)
+		assert.Equal(t, []sarifResult{
+			{RuleID: "other-rule", Level: "error", Message: sarifMessage{Text: "something was wrong"}},
+		}, run.Results)
+	})
+}
@@ -0,0 +1,150 @@
+package main
+
+import (
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/scanner"
+	"go/token"
+	"sort"
+	"strings"
+)
+
+// These are the allowable values for the "-stale" option.
+const (
+	staleModeWarn   = "warn"
+	staleModeFail   = "fail"
+	staleModeIgnore = "ignore"
+)
+
+var staleModes = []string{staleModeWarn, staleModeFail, staleModeIgnore}
+
+// maxStaleRangesShown is the number of stale ranges that are listed in the text output; if the
+// profile is badly out of date, there may be a great many of them.
+const maxStaleRangesShown = 10
+
+// StaleRange is a code range in the coverage profile that does not match the current source code,
+// which usually means that the source code has changed since the profile was generated.
+type StaleRange struct {
+	CodeRange CodeRange
+
+	// Reason is a human-readable description of the mismatch.
+	Reason string
+}
+
+func getStaleModeNames() string {
+	return strings.Join(staleModes, ", ")
+}
+
+func isValidStaleMode(s string) bool {
+	for _, m := range staleModes {
+		if s == m {
+			return true
+		}
+	}
+	return false
+}
+
+func shouldCheckStaleRanges(opts EnforcerOptions) bool {
+	return opts.StaleMode == staleModeWarn || opts.StaleMode == staleModeFail
+}
+
+// findStaleRanges checks the code ranges from a single file against the file's current content.
+// Every range must be within the file's lines and columns. If the file can be parsed as Go source
+// code, each range must also start and end between tokens rather than in the middle of one, and a
+// range that contains any statements must contain the start of at least one statement. These are
+// loose checks, since the exact boundaries that "go test" uses for code blocks vary between Go
+// versions, but a range from an older version of the file is unlikely to pass all of them.
+func findStaleRanges(ranges []CodeRange, statementCounts []int, source []byte) []StaleRange {
+	lines := strings.Split(string(source), "\n")
+	if n := len(lines); n > 1 && lines[n-1] == "" {
+		lines = lines[:n-1]
+	}
+	lineOffsets := getLineOffsets(source)
+	tokenSpans, statementStarts, parsed := getTokenSpansAndStatementStarts(source)
+
+	var ret []StaleRange
+	for i, r := range ranges {
+		reason := ""
+		switch {
+		case r.StartLine < 1 || r.EndLine < r.StartLine:
+			reason = "not a valid code range"
+		case r.EndLine > len(lines):
+			reason = fmt.Sprintf("line %d is beyond the end of the file (%d lines)", r.EndLine, len(lines))
+		case r.StartColumn > len(lines[r.StartLine-1])+1:
+			reason = fmt.Sprintf("column %d is beyond the end of line %d", r.StartColumn, r.StartLine)
+		case r.EndColumn > len(lines[r.EndLine-1])+1:
+			reason = fmt.Sprintf("column %d is beyond the end of line %d", r.EndColumn, r.EndLine)
+		case parsed:
+			start, _ := getSourceOffset(lineOffsets, len(source), r.StartLine, r.StartColumn)
+			end, _ := getSourceOffset(lineOffsets, len(source), r.EndLine, r.EndColumn)
+			switch {
+			case isInsideToken(tokenSpans, start):
+				reason = fmt.Sprintf("start (line %d, column %d) is in the middle of a token", r.StartLine, r.StartColumn)
+			case isInsideToken(tokenSpans, end):
+				reason = fmt.Sprintf("end (line %d, column %d) is in the middle of a token", r.EndLine, r.EndColumn)
+			case statementCounts[i] > 0 && !containsOffset(statementStarts, start, end):
+				reason = "range does not contain the start of any statement"
+			}
+		}
+		if reason != "" {
+			ret = append(ret, StaleRange{CodeRange: r, Reason: reason})
+		}
+	}
+	return ret
+}
+
+// tokenSpan is the byte offset of the start of a token and the offset just past its end.
+type tokenSpan struct {
+	start, end int
+}
+
+// getTokenSpansAndStatementStarts scans and parses the source code, returning the spans of all
+// tokens and comments and the starting offsets of all statements, each sorted in ascending order.
+// It returns false if the source code is not valid Go.
+func getTokenSpansAndStatementStarts(source []byte) ([]tokenSpan, []int, bool) {
+	fset := token.NewFileSet()
+	file, err := parser.ParseFile(fset, "", source, parser.ParseComments)
+	if err != nil {
+		return nil, nil, false
+	}
+	var statementStarts []int
+	ast.Inspect(file, func(n ast.Node) bool {
+		if s, ok := n.(ast.Stmt); ok {
+			statementStarts = append(statementStarts, fset.Position(s.Pos()).Offset)
+		}
+		return true
+	})
+	sort.Ints(statementStarts)
+
+	var spans []tokenSpan
+	scanFile := token.NewFileSet().AddFile("", -1, len(source))
+	var s scanner.Scanner
+	s.Init(scanFile, source, nil, scanner.ScanComments)
+	for {
+		pos, tok, lit := s.Scan()
+		if tok == token.EOF {
+			break
+		}
+		length := len(lit)
+		switch {
+		case tok == token.SEMICOLON && lit == "\n": // automatically inserted, so it takes up no space
+			continue
+		case !tok.IsLiteral() && tok != token.COMMENT:
+			length = len(tok.String())
+		}
+		start := scanFile.Offset(pos)
+		spans = append(spans, tokenSpan{start: start, end: start + length})
+	}
+	return spans, statementStarts, true
+}
+
+func isInsideToken(spans []tokenSpan, offset int) bool {
+	i := sort.Search(len(spans), func(i int) bool { return spans[i].end > offset })
+	return i < len(spans) && spans[i].start < offset
+}
+
+func containsOffset(sortedOffsets []int, start, end int) bool {
+	i := sort.SearchInts(sortedOffsets, start)
+	return i < len(sortedOffsets) && sortedOffsets[i] < end
+}
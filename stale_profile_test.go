@@ -0,0 +1,183 @@
+package main
+
+import (
+	"bytes"
+	"io/ioutil"
+	"regexp"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFindStaleRanges(t *testing.T) {
+	source, err := ioutil.ReadFile(testDataDir + "/gosource/sample.go")
+	require.NoError(t, err)
+
+	check := func(r CodeRange, statementCount int) []StaleRange {
+		return findStaleRanges([]CodeRange{r}, []int{statementCount}, source)
+	}
+	staleReason := func(r CodeRange, statementCount int) string {
+		ret := check(r, statementCount)
+		if len(ret) != 1 {
+			return ""
+		}
+		return ret[0].Reason
+	}
+
+	t.Run("ranges that match the source code", func(t *testing.T) {
+		withValidTestProfile(testDataCountsFile, func(cp *CoverageProfile) {
+			var ranges []CodeRange
+			var statementCounts []int
+			for _, b := range cp.Blocks {
+				ranges = append(ranges, b.CodeRange)
+				statementCounts = append(statementCounts, b.StatementCount)
+			}
+			assert.Nil(t, findStaleRanges(ranges, statementCounts, source))
+		})
+		// newer versions of "go test" use ranges that end at the end of the last statement
+		assert.Nil(t, check(CodeRange{"a", 4, 2, 4, 14}, 1))
+		assert.Nil(t, check(CodeRange{"a", 18, 3, 19, 1}, 1))
+		// a block with no statements may be empty
+		assert.Nil(t, check(CodeRange{"a", 5, 2, 5, 2}, 0))
+	})
+
+	t.Run("ranges that are outside of the file", func(t *testing.T) {
+		assert.Equal(t, "line 22 is beyond the end of the file (21 lines)", staleReason(CodeRange{"a", 20, 2, 22, 2}, 1))
+		assert.Equal(t, "column 30 is beyond the end of line 3", staleReason(CodeRange{"a", 3, 30, 5, 2}, 1))
+		assert.Equal(t, "column 9 is beyond the end of line 5", staleReason(CodeRange{"a", 3, 24, 5, 9}, 1))
+		assert.Equal(t, "not a valid code range", staleReason(CodeRange{"a", 5, 1, 3, 1}, 1))
+		assert.Equal(t, "not a valid code range", staleReason(CodeRange{"a", 0, 1, 3, 1}, 1))
+	})
+
+	t.Run("ranges that are not at statement boundaries", func(t *testing.T) {
+		assert.Equal(t, "start (line 4, column 4) is in the middle of a token", staleReason(CodeRange{"a", 4, 4, 5, 2}, 1))
+		assert.Equal(t, "end (line 4, column 5) is in the middle of a token", staleReason(CodeRange{"a", 4, 2, 4, 5}, 1))
+		assert.Equal(t, "range does not contain the start of any statement", staleReason(CodeRange{"a", 4, 9, 4, 14}, 1))
+	})
+
+	t.Run("only line and column checks for a file that is not Go source code", func(t *testing.T) {
+		text := []byte("first line\nsecond line\n")
+		assert.Nil(t, findStaleRanges([]CodeRange{{"a", 1, 3, 2, 4}}, []int{1}, text))
+		ret := findStaleRanges([]CodeRange{{"a", 1, 3, 3, 1}}, []int{1}, text)
+		require.Len(t, ret, 1)
+		assert.Equal(t, "line 3 is beyond the end of the file (2 lines)", ret[0].Reason)
+	})
+}
+
+func TestAnalyzeCoverageWithStaleProfile(t *testing.T) {
+	t.Run("finds stale ranges", func(t *testing.T) {
+		withValidTestProfile(testDataDiffOldFile, func(cp *CoverageProfile) {
+			opts := testBaseOptions
+			opts.StaleMode = staleModeWarn
+			result, err := AnalyzeCoverage(cp, opts)
+			require.NoError(t, err)
+			require.Len(t, result.StaleRanges, 6)
+			assert.Equal(t, StaleRange{
+				CodeRange: CodeRange{testDataDiffFilePath, 7, 24, 9, 2},
+				Reason:    "column 24 is beyond the end of line 7",
+			}, result.StaleRanges[0])
+		})
+	})
+
+	t.Run("profile that is not stale", func(t *testing.T) {
+		withValidTestProfile(testDataCountsFile, func(cp *CoverageProfile) {
+			opts := testBaseOptions
+			opts.StaleMode = staleModeFail
+			result, err := AnalyzeCoverage(cp, opts)
+			require.NoError(t, err)
+			assert.Nil(t, result.StaleRanges)
+		})
+	})
+
+	t.Run("skipped blocks are still checked", func(t *testing.T) {
+		withValidTestProfile(testDataDiffOldFile, func(cp *CoverageProfile) {
+			opts := testBaseOptions
+			opts.StaleMode = staleModeWarn
+			opts.SkipCodePattern = regexp.MustCompile("Get")
+			result, err := AnalyzeCoverage(cp, opts)
+			require.NoError(t, err)
+			require.Len(t, result.SkippedBlocks, 1)
+			require.Len(t, result.StaleRanges, 6)
+			assert.Equal(t, StaleRange{
+				CodeRange: CodeRange{testDataDiffFilePath, 14, 12, 16, 3},
+				Reason:    "column 12 is beyond the end of line 14",
+			}, result.StaleRanges[2])
+		})
+	})
+
+	t.Run("source file that cannot be read", func(t *testing.T) {
+		file := AnalyzerFileResult{Blocks: []CodeBlockCoverage{{CodeRange: CodeRange{"a", 1, 1, 2, 1}, StatementCount: 1}}}
+		assert.Nil(t, checkFileForStaleRanges(file, "a", nil, "no-such-file"))
+	})
+
+	t.Run("not checked in ignore mode", func(t *testing.T) {
+		withValidTestProfile(testDataDiffOldFile, func(cp *CoverageProfile) {
+			opts := testBaseOptions
+			opts.StaleMode = staleModeIgnore
+			result, err := AnalyzeCoverage(cp, opts)
+			require.NoError(t, err)
+			assert.Nil(t, result.StaleRanges)
+		})
+	})
+}
+
+func TestSummaryReportWithStaleProfile(t *testing.T) {
+	staleRanges := make([]StaleRange, maxStaleRangesShown+2)
+	for i := range staleRanges {
+		staleRanges[i] = StaleRange{CodeRange: CodeRange{"a/b.go", i + 1, 1, i + 2, 1}, Reason: "bad"}
+	}
+	result := AnalyzerResult{StaleRanges: staleRanges}
+
+	t.Run("warn mode", func(t *testing.T) {
+		opts := EnforcerOptions{StaleMode: staleModeWarn}
+		r := NewSummaryReport(result, opts)
+		assert.True(t, r.Pass)
+		assert.Equal(t, []SummaryReportRule{
+			{Name: uncoveredBlocksRuleName, Pass: true, Message: "no uncovered blocks"},
+			{Name: staleProfileRuleName, Pass: true, Message: "12 code range(s) in the profile do not match the source code"},
+		}, r.Rules)
+
+		buf := new(bytes.Buffer)
+		assert.True(t, r.Output(buf, opts))
+		assert.Equal(t, `The coverage profile does not match the source code; it may be out of date, so these results may be wrong:
+a/b.go 1-2: bad
+a/b.go 2-3: bad
+a/b.go 3-4: bad
+a/b.go 4-5: bad
+a/b.go 5-6: bad
+a/b.go 6-7: bad
+a/b.go 7-8: bad
+a/b.go 8-9: bad
+a/b.go 9-10: bad
+a/b.go 10-11: bad
+... and 2 more
+
+Coverage scan passes!
+`, buf.String())
+	})
+
+	t.Run("fail mode", func(t *testing.T) {
+		opts := EnforcerOptions{StaleMode: staleModeFail}
+		r := NewSummaryReport(result, opts)
+		assert.False(t, r.Pass)
+
+		buf := new(bytes.Buffer)
+		assert.False(t, r.Output(buf, opts))
+		assert.Contains(t, buf.String(),
+			"\nFailed rule stale-profile: 12 code range(s) in the profile do not match the source code\n")
+	})
+
+	t.Run("fail mode with no stale ranges", func(t *testing.T) {
+		opts := EnforcerOptions{StaleMode: staleModeFail}
+		r := NewSummaryReport(AnalyzerResult{}, opts)
+		assert.True(t, r.Pass)
+		assert.Equal(t, SummaryReportRule{Name: staleProfileRuleName, Pass: true, Message: "profile matches the source code"},
+			r.Rules[1])
+	})
+
+	t.Run("ignore mode", func(t *testing.T) {
+		r := NewSummaryReport(AnalyzerResult{}, EnforcerOptions{StaleMode: staleModeIgnore})
+		assert.Len(t, r.Rules, 1)
+	})
+}
@@ -4,15 +4,23 @@ import (
 	"fmt"
 	"io"
 	"sort"
+	"strings"
 	"text/tabwriter"
 )
 
 // SummaryReport is a post-processed version of the data from AnalyzerResult, corresponding to how we will
 // display the information.
 type SummaryReport struct {
-	Packages        []SummaryReportPackage
-	UncoveredBlocks []UncoveredBlock
-	Pass            bool
+	Packages         []SummaryReportPackage
+	UncoveredBlocks  []UncoveredBlock
+	PartialLines     []SummaryReportPartialLine
+	ColdBlocks       []CodeBlockCoverage
+	HotBlocks        []CodeBlockCoverage
+	SkippedFilePaths []string
+	SkippedBlocks    []SkippedBlock
+	StaleRanges      []StaleRange
+	Rules            []SummaryReportRule
+	Pass             bool
 }
 
 type SummaryReportPackage struct {
@@ -22,24 +30,139 @@ type SummaryReportPackage struct {
 }
 
 type SummaryReportFile struct {
-	FileName string
-	Coverage SummaryReportCoverage
+	FileName  string
+	Coverage  SummaryReportCoverage
+	Functions []SummaryReportFunction
 }
 
+// SummaryReportFunction is the coverage of a single function, as described for AnalyzerFunctionResult.
+type SummaryReportFunction struct {
+	Name      string
+	StartLine int
+	Coverage  SummaryReportCoverage
+}
+
+// SummaryReportPartialLine is a source line that contains parts of both covered and uncovered
+// blocks, as described for getPartialLines.
+type SummaryReportPartialLine struct {
+	// FilePath is the path of the source file as it appears in the coverage profile.
+	FilePath string
+
+	// Line is the line number.
+	Line int
+}
+
+// SummaryReportRule is the outcome of one of the checks that determine whether the coverage scan
+// passes. The scan passes only if every rule passes.
+type SummaryReportRule struct {
+	// Name is a short identifier for the rule, such as "uncovered-blocks".
+	Name string
+
+	// Pass is true if the rule was satisfied.
+	Pass bool
+
+	// Message is a human-readable description of the outcome.
+	Message string
+}
+
+const (
+	uncoveredBlocksRuleName = "uncovered-blocks"
+	partialLinesRuleName    = "partial-lines"
+	staleProfileRuleName    = "stale-profile"
+)
+
+// These are the allowable values for the "-metric" option.
+const (
+	metricStatements = "statements"
+	metricLines      = "lines"
+	metricBlocks     = "blocks"
+)
+
+var coverageMetrics = []string{metricStatements, metricLines, metricBlocks}
+
+func getCoverageMetricNames() string {
+	return strings.Join(coverageMetrics, ", ")
+}
+
+func isValidCoverageMetric(s string) bool {
+	for _, m := range coverageMetrics {
+		if s == m {
+			return true
+		}
+	}
+	return false
+}
+
+// SummaryReportCoverage describes the coverage of a function, file, package, or directory in terms
+// of statements, lines, and blocks. A line is counted as covered only if every block that includes
+// any part of it was covered.
 type SummaryReportCoverage struct {
 	TotalStatements   int
 	CoveredStatements int
+	TotalLines        int
+	CoveredLines      int
+	TotalBlocks       int
+	CoveredBlocks     int
 }
 
+// GetCoveredPercent returns the percentage of statements that were covered, rounded down.
 func (c SummaryReportCoverage) GetCoveredPercent() int {
-	if c.TotalStatements == 0 {
+	return c.GetMetricPercent(metricStatements)
+}
+
+// GetMetric returns the covered and total counts for the specified metric, which is one of the
+// values allowed for the "-metric" option. An empty string is treated as "statements".
+func (c SummaryReportCoverage) GetMetric(metric string) (covered, total int) {
+	switch metric {
+	case metricLines:
+		return c.CoveredLines, c.TotalLines
+	case metricBlocks:
+		return c.CoveredBlocks, c.TotalBlocks
+	default:
+		return c.CoveredStatements, c.TotalStatements
+	}
+}
+
+// GetMetricPercent returns the percentage of the specified metric that was covered, rounded down.
+// It is 100 if the total is zero.
+func (c SummaryReportCoverage) GetMetricPercent(metric string) int {
+	covered, total := c.GetMetric(metric)
+	if total == 0 {
 		return 100
 	}
-	return c.CoveredStatements * 100 / c.TotalStatements
+	return covered * 100 / total
+}
+
+func (c *SummaryReportCoverage) add(other SummaryReportCoverage) {
+	c.TotalStatements += other.TotalStatements
+	c.CoveredStatements += other.CoveredStatements
+	c.TotalLines += other.TotalLines
+	c.CoveredLines += other.CoveredLines
+	c.TotalBlocks += other.TotalBlocks
+	c.CoveredBlocks += other.CoveredBlocks
+}
+
+// GetTotalCoverage returns the sum of the coverage statistics of all packages.
+func (r SummaryReport) GetTotalCoverage() SummaryReportCoverage {
+	var c SummaryReportCoverage
+	for _, p := range r.Packages {
+		c.add(p.Coverage)
+	}
+	return c
+}
+
+// GetSkippedStatementCount returns the total number of statements in all skipped files and blocks.
+func (r SummaryReport) GetSkippedStatementCount() int {
+	n := 0
+	for _, b := range r.SkippedBlocks {
+		n += b.StatementCount
+	}
+	return n
 }
 
 func NewSummaryReport(result AnalyzerResult, opts EnforcerOptions) SummaryReport {
-	var r SummaryReport
+	r := SummaryReport{SkippedFilePaths: result.SkippedFilePaths, SkippedBlocks: result.SkippedBlocks,
+		StaleRanges: result.StaleRanges}
 	for _, p := range result.Packages {
 		var rp SummaryReportPackage
 		rp.FullPackagePath = opts.PackagePath
@@ -47,77 +170,265 @@ func NewSummaryReport(result AnalyzerResult, opts EnforcerOptions) SummaryReport
 			rp.FullPackagePath += "/" + p.RelativePath
 		}
 		for _, f := range p.Files {
-			rp.Coverage.TotalStatements += f.TotalStatements
-			rp.Coverage.CoveredStatements += f.CoveredStatements
-			rp.Files = append(rp.Files, SummaryReportFile{
-				FileName: f.FileName,
-				Coverage: SummaryReportCoverage{
-					TotalStatements: f.TotalStatements, CoveredStatements: f.CoveredStatements},
-			})
+			rf := SummaryReportFile{FileName: f.FileName, Coverage: getBlocksCoverage(f.Blocks, getMinHits(opts))}
+			rf.Coverage.TotalStatements, rf.Coverage.CoveredStatements = f.TotalStatements, f.CoveredStatements
+			rp.Coverage.add(rf.Coverage)
+			for _, fn := range f.Functions {
+				rfn := SummaryReportFunction{Name: fn.Name, StartLine: fn.StartLine, Coverage: getBlocksCoverage(fn.Blocks, getMinHits(opts))}
+				rfn.Coverage.TotalStatements, rfn.Coverage.CoveredStatements = fn.TotalStatements, fn.CoveredStatements
+				rf.Functions = append(rf.Functions, rfn)
+			}
+			rp.Files = append(rp.Files, rf)
 			r.UncoveredBlocks = append(r.UncoveredBlocks, f.UncoveredBlocks...)
+			for _, line := range getPartialLines(f.Blocks, getMinHits(opts)) {
+				r.PartialLines = append(r.PartialLines,
+					SummaryReportPartialLine{FilePath: rp.FullPackagePath + "/" + f.FileName, Line: line})
+			}
 		}
 		r.Packages = append(r.Packages, rp)
 	}
 	sort.Slice(r.Packages, func(i, j int) bool {
 		return r.Packages[i].FullPackagePath < r.Packages[j].FullPackagePath
 	})
-	r.Pass = len(r.UncoveredBlocks) == 0
+	r.ColdBlocks, r.HotBlocks = getColdAndHotBlocks(result, opts)
+	uncoveredRule := SummaryReportRule{Name: uncoveredBlocksRuleName, Pass: len(r.UncoveredBlocks) == 0}
+	if uncoveredRule.Pass {
+		uncoveredRule.Message = "no uncovered blocks"
+	} else {
+		uncoveredRule.Message = fmt.Sprintf("%d uncovered block(s) detected", len(r.UncoveredBlocks))
+	}
+	r.Rules = append(r.Rules, uncoveredRule)
+
+	if opts.CheckPartialLines {
+		r.Rules = append(r.Rules, SummaryReportRule{
+			Name: partialLinesRuleName,
+			Pass: len(r.PartialLines) <= opts.MaxPartialLines,
+			Message: fmt.Sprintf("%d partially covered line(s), maximum is %d",
+				len(r.PartialLines), opts.MaxPartialLines),
+		})
+	}
+
+	if shouldCheckStaleRanges(opts) {
+		staleRule := SummaryReportRule{Name: staleProfileRuleName, Pass: len(r.StaleRanges) == 0 || opts.StaleMode != staleModeFail}
+		if len(r.StaleRanges) == 0 {
+			staleRule.Message = "profile matches the source code"
+		} else {
+			staleRule.Message = fmt.Sprintf("%d code range(s) in the profile do not match the source code", len(r.StaleRanges))
+		}
+		r.Rules = append(r.Rules, staleRule)
+	}
+
+	r.Pass = true
+	for _, rule := range r.Rules {
+		r.Pass = r.Pass && rule.Pass
+	}
 	return r
 }
 
 func (r SummaryReport) Output(writer io.Writer, opts EnforcerOptions) bool {
+	if len(r.StaleRanges) > 0 {
+		r.outputStaleRanges(writer, opts)
+	}
+
 	if opts.ShowPackageStats || opts.ShowFileStats {
 		tw := tabwriter.NewWriter(writer, 1, 4, 1, ' ', 0)
-		for _, p := range r.Packages {
-			if opts.ShowPackageStats {
-				fmt.Fprintf(tw, "%s\t%d/%d\t(%d%%)\t\n",
-					p.FullPackagePath,
-					p.Coverage.CoveredStatements,
-					p.Coverage.TotalStatements,
-					p.Coverage.GetCoveredPercent(),
-				)
-			}
-			if opts.ShowFileStats {
-				for _, f := range p.Files {
-					var desc string
-					if opts.ShowPackageStats {
-						desc = "  " + f.FileName
-					} else {
-						desc = p.FullPackagePath + "/" + f.FileName
+		if opts.ShowPackageStats {
+			for _, p := range r.getSortedPackages(opts) {
+				writeStatsRow(tw, p.FullPackagePath, p.Coverage, opts)
+				if opts.ShowFileStats {
+					for _, f := range getSortedFiles(p, opts) {
+						writeStatsRow(tw, "  "+f.FileName, f.Coverage, opts)
 					}
-					fmt.Fprintf(tw, "%s\t%d/%d\t(%d%%)\t\n",
-						desc,
-						f.Coverage.CoveredStatements,
-						f.Coverage.TotalStatements,
-						f.Coverage.GetCoveredPercent(),
-					)
 				}
 			}
+		} else {
+			for _, f := range r.getSortedFileRows(opts) {
+				writeStatsRow(tw, f.FilePath, f.Coverage, opts)
+			}
 		}
 		tw.Flush()
 	}
 
-	if opts.ShowPackageStats || opts.ShowFileStats {
+	if opts.ShowPackageTree {
+		if opts.ShowPackageStats || opts.ShowFileStats {
+			fmt.Fprintln(writer)
+		}
+		tw := tabwriter.NewWriter(writer, 1, 4, 1, ' ', 0)
+		for _, n := range r.GetPackageTree(opts.PackagePath, opts.PackageTreeDepth) {
+			writeStatsRow(tw, strings.Repeat("  ", n.Depth)+n.GetName(opts.PackagePath), n.Coverage, opts)
+		}
+		tw.Flush()
+	}
+
+	if opts.ShowFunctionStats {
+		if opts.ShowPackageStats || opts.ShowFileStats || opts.ShowPackageTree {
+			fmt.Fprintln(writer)
+		}
+		tw := tabwriter.NewWriter(writer, 1, 4, 1, ' ', 0)
+		for _, p := range r.Packages {
+			for _, f := range p.Files {
+				for _, fn := range f.Functions {
+					writeStatsRow(tw, fmt.Sprintf("%s/%s:%d:\t%s", p.FullPackagePath, f.FileName, fn.StartLine, fn.Name),
+						fn.Coverage, opts)
+				}
+			}
+		}
+		writeStatsRow(tw, fmt.Sprintf("total:\t(%s)", getEffectiveMetric(opts)), r.GetTotalCoverage(), opts)
+		tw.Flush()
+	}
+
+	if opts.ShowPackageStats || opts.ShowFileStats || opts.ShowPackageTree || opts.ShowFunctionStats {
+		fmt.Fprintln(writer)
+	}
+
+	if opts.ShowSkipped {
+		r.outputSkipped(writer, opts)
+	}
+
+	if opts.ShowPartialLines {
+		fmt.Fprintln(writer, "Partially covered lines:")
+		for _, pl := range r.PartialLines {
+			fmt.Fprintf(writer, "%s:%d\n", pl.FilePath, pl.Line)
+		}
+		fmt.Fprintf(writer, "%d partially covered line(s)\n\n", len(r.PartialLines))
+	}
+
+	if opts.ColdBlockHits > 0 {
+		fmt.Fprintf(writer, "Cold blocks (executed at most %s):\n", describeHitCount(opts.ColdBlockHits))
+		writeBlockHitCounts(writer, r.ColdBlocks)
+		fmt.Fprintln(writer)
+	}
+
+	if opts.HotBlockCount > 0 {
+		fmt.Fprintln(writer, "Hot blocks (most frequently executed):")
+		writeBlockHitCounts(writer, r.HotBlocks)
 		fmt.Fprintln(writer)
 	}
 
 	if r.Pass {
-		fmt.Fprintln(writer, "Coverage scan passes!")
+		fmt.Fprintln(writer, colorize("Coverage scan passes!", ansiGreen, opts))
 		return true
 	}
 
-	fmt.Fprintln(writer, "Uncovered blocks detected:")
-	for _, b := range r.UncoveredBlocks {
+	if len(r.UncoveredBlocks) > 0 {
+		r.outputUncoveredBlocks(writer, opts)
+	}
+	for _, rule := range r.Rules {
+		if !rule.Pass && rule.Name != uncoveredBlocksRuleName {
+			fmt.Fprintln(writer, colorize(fmt.Sprintf("Failed rule %s: %s", rule.Name, rule.Message), ansiRed, opts))
+		}
+	}
+
+	return false
+}
+
+func (r SummaryReport) outputStaleRanges(writer io.Writer, opts EnforcerOptions) {
+	fmt.Fprintln(writer, colorize("The coverage profile does not match the source code; it may be out of date, so these results may be wrong:",
+		ansiYellow, opts))
+	for i, sr := range r.StaleRanges {
+		if i == maxStaleRangesShown {
+			fmt.Fprintf(writer, "... and %d more\n", len(r.StaleRanges)-i)
+			break
+		}
+		fmt.Fprintf(writer, "%s %d-%d: %s\n", sr.CodeRange.FilePath, sr.CodeRange.StartLine, sr.CodeRange.EndLine, sr.Reason)
+	}
+	fmt.Fprintln(writer)
+}
+
+func (r SummaryReport) outputUncoveredBlocks(writer io.Writer, opts EnforcerOptions) {
+	fmt.Fprintln(writer, colorize("Uncovered blocks detected:", ansiRed, opts))
+	blocks := r.getSortedUncoveredBlocks(opts)
+	for i, b := range blocks {
+		if opts.MaxBlocks > 0 && i == opts.MaxBlocks {
+			if opts.ShowCode {
+				fmt.Fprintln(writer)
+			}
+			fmt.Fprintf(writer, "... and %d more\n", len(blocks)-i)
+			break
+		}
 		if opts.ShowCode {
 			fmt.Fprintln(writer)
 		}
-		fmt.Fprintf(writer, "%s %d-%d\n", b.CodeRange.FilePath, b.CodeRange.StartLine, b.CodeRange.EndLine)
+		fmt.Fprintf(writer, "%s %d-%d", b.CodeRange.FilePath, b.CodeRange.StartLine, b.CodeRange.EndLine)
+		switch {
+		case opts.MergeBlocks && b.FunctionName != "":
+			fmt.Fprintf(writer, " (%s in %s)", describeStatementCount(b.StatementCount), b.FunctionName)
+		case opts.MergeBlocks:
+			fmt.Fprintf(writer, " (%s)", describeStatementCount(b.StatementCount))
+		case b.FunctionName != "":
+			fmt.Fprintf(writer, " (in %s)", b.FunctionName)
+		}
+		if b.CoverageCount > 0 {
+			fmt.Fprintf(writer, " (executed %s, minimum is %d)", describeHitCount(b.CoverageCount), getMinHits(opts))
+		}
+		fmt.Fprintln(writer)
 		if opts.ShowCode {
-			for i, line := range b.Text {
-				fmt.Fprintf(writer, "%d>\t%s\n", b.CodeRange.StartLine+i, line)
-			}
+			writeCodeExcerpt(writer, b, opts)
 		}
 	}
+}
 
-	return false
+// writeStatsRow writes a row of a stats table, showing the metric that was selected with "-metric".
+func writeStatsRow(tw io.Writer, desc string, c SummaryReportCoverage, opts EnforcerOptions) {
+	covered, total := c.GetMetric(opts.Metric)
+	fmt.Fprintf(tw, "%s\t%d/%d\t%s\t\n", desc, covered, total, formatPercentCell(c, opts))
+}
+
+func getEffectiveMetric(opts EnforcerOptions) string {
+	if opts.Metric == "" {
+		return metricStatements
+	}
+	return opts.Metric
+}
+
+func (r SummaryReport) outputSkipped(writer io.Writer, opts EnforcerOptions) {
+	skippedFileStatements := make(map[string]int)
+	for _, b := range r.SkippedBlocks {
+		if b.Reason.Option == "skipfiles" {
+			skippedFileStatements[b.CodeRange.FilePath] += b.StatementCount
+		}
+	}
+	if len(r.SkippedFilePaths) > 0 {
+		fmt.Fprintln(writer, "Skipped files:")
+		for _, filePath := range r.SkippedFilePaths {
+			fmt.Fprintf(writer, "%s (%s): %s\n",
+				filePath,
+				describeStatementCount(skippedFileStatements[filePath]),
+				describeSkipReason(SkipReason{Option: "skipfiles", Pattern: opts.SkipFilesPattern.String()}),
+			)
+		}
+		fmt.Fprintln(writer)
+	}
+
+	headerShown := false
+	for _, b := range r.SkippedBlocks {
+		if b.Reason.Option == "skipfiles" {
+			continue
+		}
+		if !headerShown {
+			fmt.Fprintln(writer, "Skipped blocks:")
+			headerShown = true
+		}
+		fmt.Fprintf(writer, "%s %d-%d (%s): %s\n",
+			b.CodeRange.FilePath,
+			b.CodeRange.StartLine,
+			b.CodeRange.EndLine,
+			describeStatementCount(b.StatementCount),
+			describeSkipReason(b.Reason),
+		)
+	}
+	if headerShown {
+		fmt.Fprintln(writer)
+	}
+
+	fmt.Fprintf(writer, "Skipped %s in total.\n\n", describeStatementCount(r.GetSkippedStatementCount()))
+}
+
+// describeSkipReason returns a human-readable description of why a file or block was skipped.
+func describeSkipReason(reason SkipReason) string {
+	if reason.Option == "skipcode" {
+		return fmt.Sprintf(`skipped by -skipcode "%s" (line %d: %s)`,
+			reason.Pattern, reason.Line, strings.TrimSpace(reason.Text))
+	}
+	return fmt.Sprintf(`skipped by -skipfiles "%s"`, reason.Pattern)
 }
@@ -15,6 +15,17 @@ const (
 	testDataReportPassFile    = "coverage_data_for_report_pass"
 )
 
+func makeCoverage(totalStatements, coveredStatements, totalLines, coveredLines, totalBlocks, coveredBlocks int) SummaryReportCoverage {
+	return SummaryReportCoverage{
+		TotalStatements:   totalStatements,
+		CoveredStatements: coveredStatements,
+		TotalLines:        totalLines,
+		CoveredLines:      coveredLines,
+		TotalBlocks:       totalBlocks,
+		CoveredBlocks:     coveredBlocks,
+	}
+}
+
 func TestNewSummaryReport(t *testing.T) {
 	t.Run("report from data with coverage gaps", func(t *testing.T) {
 		withValidTestProfile(testDataReportNotPassFile, func(cp *CoverageProfile) {
@@ -23,41 +34,44 @@ func TestNewSummaryReport(t *testing.T) {
 			report := NewSummaryReport(result, testBaseOptions)
 
 			assert.False(t, report.Pass)
+			assert.Equal(t, []SummaryReportRule{
+				{Name: uncoveredBlocksRuleName, Pass: false, Message: "2 uncovered block(s) detected"},
+			}, report.Rules)
 
 			assert.Len(t, report.Packages, 2)
 
 			p1 := report.Packages[0]
 			assert.Equal(t, testDataPackagePath, p1.FullPackagePath)
-			assert.Equal(t, SummaryReportCoverage{8, 6}, p1.Coverage)
+			assert.Equal(t, makeCoverage(8, 6, 10, 9, 4, 3), p1.Coverage)
 			assert.Equal(t, 75, p1.Coverage.GetCoveredPercent())
 			assert.Len(t, p1.Files, 3)
 
 			p1f1 := p1.Files[0]
 			assert.Equal(t, "first", p1f1.FileName)
-			assert.Equal(t, SummaryReportCoverage{4, 2}, p1f1.Coverage)
+			assert.Equal(t, makeCoverage(4, 2, 2, 1, 2, 1), p1f1.Coverage)
 			assert.Equal(t, 50, p1f1.Coverage.GetCoveredPercent())
 
 			p1f2 := p1.Files[1]
 			assert.Equal(t, "second", p1f2.FileName)
-			assert.Equal(t, SummaryReportCoverage{4, 4}, p1f2.Coverage)
+			assert.Equal(t, makeCoverage(4, 4, 4, 4, 1, 1), p1f2.Coverage)
 			assert.Equal(t, 100, p1f2.Coverage.GetCoveredPercent())
 
 			p1f3 := p1.Files[2]
 			assert.Equal(t, "third", p1f3.FileName)
-			assert.Equal(t, SummaryReportCoverage{0, 0}, p1f3.Coverage)
+			assert.Equal(t, makeCoverage(0, 0, 4, 4, 1, 1), p1f3.Coverage)
 			// It shouldn't be possible for a block to have 0 statements, but this verifies that if such a
 			// thing happened, we wouldn't get a divide-by-zero error.
 			assert.Equal(t, 100, p1f3.Coverage.GetCoveredPercent())
 
 			p2 := report.Packages[1]
 			assert.Equal(t, testDataPackagePath+"/otherpackage", p2.FullPackagePath)
-			assert.Equal(t, SummaryReportCoverage{7, 0}, p2.Coverage)
+			assert.Equal(t, makeCoverage(7, 0, 2, 0, 1, 0), p2.Coverage)
 			assert.Equal(t, 0, p2.Coverage.GetCoveredPercent())
 			assert.Len(t, p2.Files, 1)
 
 			p2f1 := p2.Files[0]
 			assert.Equal(t, "first", p2f1.FileName)
-			assert.Equal(t, SummaryReportCoverage{7, 0}, p2f1.Coverage)
+			assert.Equal(t, makeCoverage(7, 0, 2, 0, 1, 0), p2f1.Coverage)
 			assert.Equal(t, 0, p2f1.Coverage.GetCoveredPercent())
 
 			var blocks []UncoveredBlock
@@ -65,6 +79,8 @@ func TestNewSummaryReport(t *testing.T) {
 			blocks = append(blocks, result.Packages[0].Files[1].UncoveredBlocks...)
 			blocks = append(blocks, result.Packages[1].Files[0].UncoveredBlocks...)
 			assert.Equal(t, blocks, report.UncoveredBlocks)
+
+			assert.Equal(t, makeCoverage(15, 6, 12, 9, 5, 3), report.GetTotalCoverage())
 		})
 	})
 
@@ -75,23 +91,26 @@ func TestNewSummaryReport(t *testing.T) {
 			report := NewSummaryReport(result, testBaseOptions)
 
 			assert.True(t, report.Pass)
+			assert.Equal(t, []SummaryReportRule{
+				{Name: uncoveredBlocksRuleName, Pass: true, Message: "no uncovered blocks"},
+			}, report.Rules)
 
 			assert.Len(t, report.Packages, 1)
 
 			p1 := report.Packages[0]
 			assert.Equal(t, testDataPackagePath, p1.FullPackagePath)
-			assert.Equal(t, SummaryReportCoverage{6, 6}, p1.Coverage)
+			assert.Equal(t, makeCoverage(6, 6, 5, 5, 2, 2), p1.Coverage)
 			assert.Equal(t, 100, p1.Coverage.GetCoveredPercent())
 			assert.Len(t, p1.Files, 2)
 
 			p1f1 := p1.Files[0]
 			assert.Equal(t, "first", p1f1.FileName)
-			assert.Equal(t, SummaryReportCoverage{2, 2}, p1f1.Coverage)
+			assert.Equal(t, makeCoverage(2, 2, 1, 1, 1, 1), p1f1.Coverage)
 			assert.Equal(t, 100, p1f1.Coverage.GetCoveredPercent())
 
 			p1f2 := p1.Files[1]
 			assert.Equal(t, "second", p1f2.FileName)
-			assert.Equal(t, SummaryReportCoverage{4, 4}, p1f2.Coverage)
+			assert.Equal(t, makeCoverage(4, 4, 4, 4, 1, 1), p1f2.Coverage)
 			assert.Equal(t, 100, p1f2.Coverage.GetCoveredPercent())
 
 			assert.Len(t, report.UncoveredBlocks, 0)
@@ -166,6 +185,228 @@ base-package/otherpackage/first 1-3
 	assert.Equal(t, expected, s)
 }
 
+func TestReportOutputWithPackageTree(t *testing.T) {
+	withValidTestProfile(testDataReportNotPassFile, func(cp *CoverageProfile) {
+		opts := testBaseOptions
+		opts.ShowPackageTree = true
+		result, err := AnalyzeCoverage(cp, opts)
+		require.NoError(t, err)
+		report := NewSummaryReport(result, opts)
+
+		buf := new(bytes.Buffer)
+		report.Output(buf, opts)
+		s := regexp.MustCompile(" +\n").ReplaceAllString(buf.String(), "\n")
+		assert.Equal(t, `base-package   6/15 (40%)
+  otherpackage 0/7  (0%)
+
+Uncovered blocks detected:
+base-package/first 3-4
+base-package/otherpackage/first 1-3
+`, s)
+	})
+}
+
+func TestReportOutputWithPackageTreeAndPackageStats(t *testing.T) {
+	withValidTestProfile(testDataReportNotPassFile, func(cp *CoverageProfile) {
+		opts := testBaseOptions
+		opts.ShowPackageTree = true
+		opts.ShowPackageStats = true
+		result, err := AnalyzeCoverage(cp, opts)
+		require.NoError(t, err)
+		report := NewSummaryReport(result, opts)
+
+		buf := new(bytes.Buffer)
+		report.Output(buf, opts)
+		s := regexp.MustCompile(" +\n").ReplaceAllString(buf.String(), "\n")
+		assert.Equal(t, `base-package              6/8 (75%)
+base-package/otherpackage 0/7 (0%)
+
+base-package   6/15 (40%)
+  otherpackage 0/7  (0%)
+
+Uncovered blocks detected:
+base-package/first 3-4
+base-package/otherpackage/first 1-3
+`, s)
+	})
+}
+
+func TestSummaryReportCoverageMetrics(t *testing.T) {
+	c := makeCoverage(10, 5, 8, 2, 4, 3)
+
+	covered, total := c.GetMetric(metricStatements)
+	assert.Equal(t, []int{5, 10}, []int{covered, total})
+	covered, total = c.GetMetric(metricLines)
+	assert.Equal(t, []int{2, 8}, []int{covered, total})
+	covered, total = c.GetMetric(metricBlocks)
+	assert.Equal(t, []int{3, 4}, []int{covered, total})
+	covered, total = c.GetMetric("")
+	assert.Equal(t, []int{5, 10}, []int{covered, total})
+
+	assert.Equal(t, 50, c.GetCoveredPercent())
+	assert.Equal(t, 25, c.GetMetricPercent(metricLines))
+	assert.Equal(t, 75, c.GetMetricPercent(metricBlocks))
+	assert.Equal(t, 100, SummaryReportCoverage{}.GetMetricPercent(metricLines))
+}
+
+func TestReportOutputWithMetric(t *testing.T) {
+	doTest := func(t *testing.T, metric, expected string) {
+		withValidTestProfile(testDataFuncsFile, func(cp *CoverageProfile) {
+			opts := testBaseOptions
+			opts.Metric = metric
+			opts.ShowFileStats = true
+			opts.ShowFunctionStats = true
+			opts.MaxBlocks = 1
+			result, err := AnalyzeCoverage(cp, opts)
+			require.NoError(t, err)
+			report := NewSummaryReport(result, opts)
+
+			buf := new(bytes.Buffer)
+			report.Output(buf, opts)
+			s := regexp.MustCompile(" +").ReplaceAllString(buf.String(), " ")
+			s = regexp.MustCompile(" \n").ReplaceAllString(s, "\n")
+			assert.Equal(t, expected, s)
+		})
+	}
+
+	t.Run("lines", func(t *testing.T) {
+		doTest(t, metricLines, `base-package/gosource/sample.go 6/15 (40%)
+
+base-package/gosource/sample.go:3: Add 3/3 (100%)
+base-package/gosource/sample.go:9: T.Inc 3/6 (50%)
+base-package/gosource/sample.go:16: T.Get 0/6 (0%)
+total: (lines) 6/15 (40%)
+
+Uncovered blocks detected:
+base-package/gosource/sample.go 10-12 (in T.Inc)
+... and 3 more
+`)
+	})
+
+	t.Run("blocks", func(t *testing.T) {
+		doTest(t, metricBlocks, `base-package/gosource/sample.go 3/7 (42%)
+
+base-package/gosource/sample.go:3: Add 1/1 (100%)
+base-package/gosource/sample.go:9: T.Inc 2/3 (66%)
+base-package/gosource/sample.go:16: T.Get 0/3 (0%)
+total: (blocks) 3/7 (42%)
+
+Uncovered blocks detected:
+base-package/gosource/sample.go 10-12 (in T.Inc)
+... and 3 more
+`)
+	})
+}
+
+func TestReportOutputWithPartialLines(t *testing.T) {
+	doTest := func(t *testing.T, opts EnforcerOptions, expectPass bool, expected string) {
+		withValidTestProfile(testDataFuncsFile, func(cp *CoverageProfile) {
+			opts.SkipCodePattern = regexp.MustCompile("func")
+			result, err := AnalyzeCoverage(cp, opts)
+			require.NoError(t, err)
+			report := NewSummaryReport(result, opts)
+
+			assert.Equal(t, []SummaryReportPartialLine{{FilePath: "base-package/gosource/sample.go", Line: 10}},
+				report.PartialLines)
+			assert.Equal(t, expectPass, report.Pass)
+
+			buf := new(bytes.Buffer)
+			report.Output(buf, opts)
+			assert.Equal(t, expected, buf.String())
+		})
+	}
+
+	t.Run("-partiallines", func(t *testing.T) {
+		opts := testBaseOptions
+		opts.ShowPartialLines = true
+		doTest(t, opts, false, `Partially covered lines:
+base-package/gosource/sample.go:10
+1 partially covered line(s)
+
+Uncovered blocks detected:
+base-package/gosource/sample.go 10-12 (in T.Inc)
+base-package/gosource/sample.go 20-21 (in T.Get)
+`)
+	})
+
+	t.Run("-maxpartial passes", func(t *testing.T) {
+		opts := testBaseOptions
+		opts.CheckPartialLines = true
+		opts.MaxPartialLines = 1
+		doTest(t, opts, false, `Uncovered blocks detected:
+base-package/gosource/sample.go 10-12 (in T.Inc)
+base-package/gosource/sample.go 20-21 (in T.Get)
+`)
+	})
+
+	t.Run("-maxpartial fails", func(t *testing.T) {
+		opts := testBaseOptions
+		opts.CheckPartialLines = true
+		opts.MaxPartialLines = 0
+		doTest(t, opts, false, `Uncovered blocks detected:
+base-package/gosource/sample.go 10-12 (in T.Inc)
+base-package/gosource/sample.go 20-21 (in T.Get)
+Failed rule partial-lines: 1 partially covered line(s), maximum is 0
+`)
+	})
+
+	t.Run("failed rule without uncovered blocks", func(t *testing.T) {
+		report := SummaryReport{
+			Rules: []SummaryReportRule{
+				{Name: uncoveredBlocksRuleName, Pass: true, Message: "no uncovered blocks"},
+				{Name: partialLinesRuleName, Pass: false, Message: "2 partially covered line(s), maximum is 1"},
+			},
+		}
+		buf := new(bytes.Buffer)
+		assert.False(t, report.Output(buf, testBaseOptions))
+		assert.Equal(t, "Failed rule partial-lines: 2 partially covered line(s), maximum is 1\n", buf.String())
+	})
+}
+
+func TestReportOutputWithSkipped(t *testing.T) {
+	t.Run("skipped files and blocks", func(t *testing.T) {
+		withValidTestProfile(testDataReportNotPassFile, func(cp *CoverageProfile) {
+			opts := testBaseOptions
+			opts.ShowSkipped = true
+			opts.SkipFilesPattern = regexp.MustCompile("sec")
+			opts.SkipCodePattern = regexp.MustCompile("^first file line 3")
+			result, err := AnalyzeCoverage(cp, opts)
+			require.NoError(t, err)
+			report := NewSummaryReport(result, opts)
+
+			assert.Equal(t, 6, report.GetSkippedStatementCount())
+
+			buf := new(bytes.Buffer)
+			report.Output(buf, opts)
+			assert.Equal(t, `Skipped files:
+base-package/second (4 statements): skipped by -skipfiles "sec"
+
+Skipped blocks:
+base-package/first 3-4 (2 statements): skipped by -skipcode "^first file line 3" (line 3: first file line 3)
+
+Skipped 6 statements in total.
+
+Uncovered blocks detected:
+base-package/otherpackage/first 1-3
+`, buf.String())
+		})
+	})
+
+	t.Run("nothing skipped", func(t *testing.T) {
+		withValidTestProfile(testDataReportPassFile, func(cp *CoverageProfile) {
+			opts := testBaseOptions
+			opts.ShowSkipped = true
+			result, err := AnalyzeCoverage(cp, opts)
+			require.NoError(t, err)
+			report := NewSummaryReport(result, opts)
+
+			buf := new(bytes.Buffer)
+			report.Output(buf, opts)
+			assert.Equal(t, "Skipped 0 statements in total.\n\nCoverage scan passes!\n", buf.String())
+		})
+	})
+}
+
 type reportOutputTestParams struct {
 	shouldPass                       bool
 	fileName                         string
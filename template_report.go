@@ -0,0 +1,56 @@
+package main
+
+import (
+	"io"
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+	"text/template"
+)
+
+// WriteTemplateReport renders the SummaryReport with the Go text/template file that was specified with
+// the "-template" option. In addition to the standard template functions, the template can use
+// the functions described in getTemplateFuncs.
+func WriteTemplateReport(writer io.Writer, report SummaryReport, result AnalyzerResult, opts EnforcerOptions) error {
+	data, err := ioutil.ReadFile(opts.TemplateFilePath)
+	if err != nil {
+		return err
+	}
+	tmpl, err := template.New(filepath.Base(opts.TemplateFilePath)).
+		Funcs(getTemplateFuncs(opts)).
+		Parse(string(data))
+	if err != nil {
+		return err
+	}
+	return tmpl.Execute(writer, report)
+}
+
+// getTemplateFuncs returns the helper functions that are available to a "-template" file:
+//
+//	percent COVERED TOTAL  the percentage (0-100) of TOTAL that COVERED represents
+//	relpath FILEPATH       a file path from the profile, made relative to the base package directory
+//	code BLOCK             the source code of an UncoveredBlock, as a single string
+//	join LIST SEPARATOR    the same as strings.Join
+func getTemplateFuncs(opts EnforcerOptions) template.FuncMap {
+	return template.FuncMap{
+		"percent": func(covered, total int) int {
+			return SummaryReportCoverage{TotalStatements: total, CoveredStatements: covered}.GetCoveredPercent()
+		},
+		"relpath": func(filePath string) string {
+			return CodeRange{FilePath: filePath}.GetRelativeFilePath(opts.PackagePath)
+		},
+		"code": func(b UncoveredBlock) (string, error) {
+			lines := b.Text
+			if lines == nil {
+				var err error
+				lines, err = readFileLines(b.CodeRange.GetRelativeFilePath(opts.PackagePath),
+					b.CodeRange.StartLine, b.CodeRange.EndLine)
+				if err != nil {
+					return "", err
+				}
+			}
+			return strings.Join(lines, "\n"), nil
+		},
+		"join": strings.Join,
+	}
+}
@@ -0,0 +1,90 @@
+package main
+
+import (
+	"bytes"
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTemplateReport(t *testing.T) {
+	t.Run("renders report with helper functions", func(t *testing.T) {
+		tmpl := `{{range .Packages}}{{.FullPackagePath}} {{percent .Coverage.CoveredStatements .Coverage.TotalStatements}}%
+{{end}}{{range .UncoveredBlocks}}{{relpath .FilePath}}:{{.StartLine}}
+{{code .}}
+{{end}}{{if .Pass}}pass{{else}}fail{{end}}
+`
+		withValidTestProfile(testDataReportNotPassFile, func(cp *CoverageProfile) {
+			var s string
+			withTemplateFile(t, tmpl, func(filePath string) {
+				opts := testBaseOptions
+				opts.TemplateFilePath = filePath
+				s = writeReportForTest(t, cp, opts, "template")
+			})
+			assert.Equal(t, `base-package 75%
+base-package/otherpackage 0%
+first:3
+first file line 3
+first file line 4
+otherpackage/first:1
+other package first file line 1
+other package first file line 2
+other package first file line 3
+fail
+`, s)
+		})
+	})
+
+	t.Run("uses text from -showcode if available", func(t *testing.T) {
+		withValidTestProfile(testDataReportNotPassFile, func(cp *CoverageProfile) {
+			opts := testBaseOptions
+			opts.ShowCode = true
+			var s string
+			withTemplateFile(t, `{{range .UncoveredBlocks}}{{join .Text "|"}};{{end}}`, func(filePath string) {
+				opts.TemplateFilePath = filePath
+				s = writeReportForTest(t, cp, opts, "template")
+			})
+			assert.Equal(t, "first file line 3|first file line 4;"+
+				"other package first file line 1|other package first file line 2|other package first file line 3;", s)
+		})
+	})
+
+	t.Run("template syntax error", func(t *testing.T) {
+		withTemplateFile(t, `{{range .Packages}}`, func(filePath string) {
+			opts := testBaseOptions
+			opts.TemplateFilePath = filePath
+			err := WriteReport(new(bytes.Buffer), "template", SummaryReport{}, AnalyzerResult{}, opts)
+			assert.Error(t, err)
+		})
+	})
+
+	t.Run("code function with source file not found", func(t *testing.T) {
+		report := SummaryReport{UncoveredBlocks: []UncoveredBlock{
+			{CodeRange: CodeRange{FilePath: testDataPackagePath + "/nonexistent_file", StartLine: 1, EndLine: 2}},
+		}}
+		withTemplateFile(t, `{{range .UncoveredBlocks}}{{code .}}{{end}}`, func(filePath string) {
+			opts := testBaseOptions
+			opts.TemplateFilePath = filePath
+			err := WriteReport(new(bytes.Buffer), "template", report, AnalyzerResult{}, opts)
+			assert.Error(t, err)
+		})
+	})
+
+	t.Run("template file not found", func(t *testing.T) {
+		opts := testBaseOptions
+		opts.TemplateFilePath = "nonexistent_file"
+		err := WriteReport(new(bytes.Buffer), "template", SummaryReport{}, AnalyzerResult{}, opts)
+		assert.Error(t, err)
+	})
+}
+
+func withTemplateFile(t *testing.T, tmpl string, action func(filePath string)) {
+	withTempDir(func(dirPath string) {
+		filePath := filepath.Join(dirPath, "report.tmpl")
+		require.NoError(t, ioutil.WriteFile(filePath, []byte(tmpl), 0600))
+		action(filePath)
+	})
+}
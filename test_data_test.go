@@ -1,6 +1,13 @@
 package main
 
-import "os"
+import (
+	"bytes"
+	"errors"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
 
 const (
 	testDataDir         = "./testdata"
@@ -41,6 +48,34 @@ func withTestProfile(filename string, action func(*CoverageProfile, error)) {
 	})
 }
 
+// writeReportForTest analyzes the coverage profile and writes a report in the specified format,
+// failing the test if either step returns an error.
+func writeReportForTest(t *testing.T, cp *CoverageProfile, opts EnforcerOptions, format string) string {
+	result, err := AnalyzeCoverage(cp, opts)
+	require.NoError(t, err)
+	report := NewSummaryReport(result, opts)
+
+	buf := new(bytes.Buffer)
+	require.NoError(t, WriteReport(buf, format, report, result, opts))
+	return buf.String()
+}
+
+// failingWriter accepts the specified number of bytes and then returns an error for every write,
+// so that tests can cause a write error at any point in a report.
+type failingWriter struct {
+	remaining int
+}
+
+func (w *failingWriter) Write(p []byte) (int, error) {
+	if len(p) > w.remaining {
+		n := w.remaining
+		w.remaining = 0
+		return n, errors.New("sorry, the write failed")
+	}
+	w.remaining -= len(p)
+	return len(p), nil
+}
+
 func withValidTestProfile(filename string, action func(*CoverageProfile)) {
 	withTestProfile(filename, func(cp *CoverageProfile, err error) {
 		if err != nil {
@@ -78,44 +113,54 @@ func makeTestAnalyzerExpectedResult() AnalyzerResult {
 }
 
 func makeTestAnalyzerExpectedResultWithCode() AnalyzerResult {
-	p1f1 := AnalyzerFileResult{FileName: "first", TotalStatements: 3, CoveredStatements: 0}
+	p1f1 := AnalyzerFileResult{FileName: "first", TotalStatements: 3, CoveredStatements: 0,
+		Blocks: []CodeBlockCoverage{expectedParsedCoverageProfile.Blocks[1], expectedParsedCoverageProfile.Blocks[0]}}
 	p1f1.UncoveredBlocks = append(p1f1.UncoveredBlocks, UncoveredBlock{
 		CodeRange: CodeRange{FilePath: testDataPackagePath + "/first",
 			StartLine: 1, StartColumn: 1, EndLine: 2, EndColumn: 1},
-		Text: []string{"first file line 1", "first file line 2"},
+		StatementCount: 2,
+		Text:           []string{"first file line 1", "first file line 2"},
 	})
 	p1f1.UncoveredBlocks = append(p1f1.UncoveredBlocks, UncoveredBlock{
 		CodeRange: CodeRange{FilePath: testDataPackagePath + "/first",
 			StartLine: 3, StartColumn: 4, EndLine: 5, EndColumn: 2},
-		Text: []string{"first file line 3", "first file line 4", "first file line 5"},
+		StatementCount: 1,
+		Text:           []string{"first file line 3", "first file line 4", "first file line 5"},
 	})
 
-	p1f2 := AnalyzerFileResult{FileName: "second", TotalStatements: 5, CoveredStatements: 0}
+	p1f2 := AnalyzerFileResult{FileName: "second", TotalStatements: 5, CoveredStatements: 0,
+		Blocks: []CodeBlockCoverage{expectedParsedCoverageProfile.Blocks[3]}}
 	p1f2.UncoveredBlocks = append(p1f2.UncoveredBlocks, UncoveredBlock{
 		CodeRange: CodeRange{FilePath: testDataPackagePath + "/second",
 			StartLine: 1, StartColumn: 1, EndLine: 5, EndColumn: 1},
-		Text: []string{"second file line 1", "second file line 2", "second file line 3", "second file line 4", "second file line 5"},
+		StatementCount: 5,
+		Text:           []string{"second file line 1", "second file line 2", "second file line 3", "second file line 4", "second file line 5"},
 	})
 
-	p1f3 := AnalyzerFileResult{FileName: "third", TotalStatements: 6, CoveredStatements: 2}
+	p1f3 := AnalyzerFileResult{FileName: "third", TotalStatements: 6, CoveredStatements: 2,
+		Blocks: []CodeBlockCoverage{expectedParsedCoverageProfile.Blocks[4], expectedParsedCoverageProfile.Blocks[6], expectedParsedCoverageProfile.Blocks[7]}}
 	p1f3.UncoveredBlocks = append(p1f3.UncoveredBlocks, UncoveredBlock{
 		CodeRange: CodeRange{FilePath: testDataPackagePath + "/third",
 			StartLine: 1, StartColumn: 1, EndLine: 2, EndColumn: 1},
-		Text: []string{"third file line 1", "third file line 2"},
+		StatementCount: 2,
+		Text:           []string{"third file line 1", "third file line 2"},
 	})
 	p1f3.UncoveredBlocks = append(p1f3.UncoveredBlocks, UncoveredBlock{
 		CodeRange: CodeRange{FilePath: testDataPackagePath + "/third",
 			StartLine: 4, StartColumn: 1, EndLine: 5, EndColumn: 1},
-		Text: []string{"third file line 4", "third file line 5"},
+		StatementCount: 2,
+		Text:           []string{"third file line 4", "third file line 5"},
 	})
 
 	p1 := AnalyzerPackageResult{RelativePath: "", Files: []AnalyzerFileResult{p1f1, p1f2, p1f3}}
 
-	p2f1 := AnalyzerFileResult{FileName: "first", TotalStatements: 1, CoveredStatements: 0}
+	p2f1 := AnalyzerFileResult{FileName: "first", TotalStatements: 1, CoveredStatements: 0,
+		Blocks: []CodeBlockCoverage{expectedParsedCoverageProfile.Blocks[2]}}
 	p2f1.UncoveredBlocks = append(p2f1.UncoveredBlocks, UncoveredBlock{
 		CodeRange: CodeRange{FilePath: testDataPackagePath + "/otherpackage/first",
 			StartLine: 2, StartColumn: 1, EndLine: 2, EndColumn: 10},
-		Text: []string{"other package first file line 2"},
+		StatementCount: 1,
+		Text:           []string{"other package first file line 2"},
 	})
 
 	p2 := AnalyzerPackageResult{RelativePath: "otherpackage", Files: []AnalyzerFileResult{p2f1}}
@@ -0,0 +1,21 @@
+package sample
+
+func Add(a, b int) int {
+	return a + b
+}
+
+type T struct{ n int }
+
+func (t *T) Inc(by int) {
+	if by < 0 {
+		panic("negative")
+	}
+	t.n += by
+}
+
+func (t T) Get() int {
+	f := func() int {
+		return t.n
+	}
+	return f()
+}